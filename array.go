@@ -0,0 +1,137 @@
+package goloquent
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// isArrayType will check whether the type is able to store as native array, it must be the slice of scalar
+func isArrayType(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice || t == typeOfByte {
+		return false
+	}
+	elem := t.Elem()
+	if elem == typeOfDecimal {
+		return true
+	}
+	return codecOf(elem) == nil && isScalarKind(elem.Kind())
+}
+
+// isNativeArray will check whether the field is stored as native array, eg. `goloquent:",array"`
+func isNativeArray(t reflect.Type, tg tag) bool {
+	if t == nil || !tg.isArray() {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return isArrayType(t)
+}
+
+// arrayValue is the value of array filter, the single value is treated as the array of one element
+func (f *Filter) arrayValue() ([]interface{}, error) {
+	if f.value == nil {
+		return nil, fmt.Errorf("goloquent: value of array filter cannot be nil")
+	}
+	vi, err := f.Interface()
+	if err != nil {
+		return nil, err
+	}
+	if x, isOk := vi.([]interface{}); isOk {
+		return x, nil
+	}
+	return []interface{}{vi}, nil
+}
+
+var arrayEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// formatArray will format the value to postgres array literal, eg. `{"a","b"}`
+func formatArray(v []interface{}) (string, error) {
+	buf := new(strings.Builder)
+	buf.WriteByte('{')
+	for i, elem := range v {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		switch vi := elem.(type) {
+		case nil:
+			buf.WriteString("NULL")
+		case string:
+			buf.WriteString(`"` + arrayEscaper.Replace(vi) + `"`)
+		case bool:
+			buf.WriteString(strconv.FormatBool(vi))
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			buf.WriteString(fmt.Sprintf("%v", vi))
+		default:
+			return "", fmt.Errorf("goloquent: unsupported array element %T", elem)
+		}
+	}
+	buf.WriteByte('}')
+	return buf.String(), nil
+}
+
+// isArrayLiteral will check whether the value is postgres array literal instead of json array
+func isArrayLiteral(b []byte) bool {
+	str := strings.TrimSpace(b2s(b))
+	return strings.HasPrefix(str, "{") && strings.HasSuffix(str, "}")
+}
+
+// parseArray will parse the one dimensional postgres array literal,
+// every element is converted to json string so it's decoded as same as json array, NULL is nil
+func parseArray(b []byte) ([][]byte, error) {
+	str := strings.TrimSpace(b2s(b))
+	if !isArrayLiteral(b) {
+		return nil, fmt.Errorf("goloquent: invalid array value %q", str)
+	}
+	str = str[1 : len(str)-1]
+	elems := make([][]byte, 0)
+	if strings.TrimSpace(str) == "" {
+		return elems, nil
+	}
+
+	for i := 0; ; i++ {
+		buf, quoted := new(strings.Builder), false
+		if i < len(str) && str[i] == '"' {
+			quoted = true
+			for i++; i < len(str) && str[i] != '"'; i++ {
+				if str[i] == '\\' && i+1 < len(str) {
+					i++
+				}
+				buf.WriteByte(str[i])
+			}
+			if i >= len(str) {
+				return nil, fmt.Errorf("goloquent: unterminated array element of %q", b2s(b))
+			}
+			i++
+		} else {
+			for ; i < len(str) && str[i] != ','; i++ {
+				if str[i] == '{' || str[i] == '"' {
+					return nil, fmt.Errorf("goloquent: unsupported array value %q", b2s(b))
+				}
+				buf.WriteByte(str[i])
+			}
+		}
+
+		elem := buf.String()
+		if !quoted && strings.EqualFold(strings.TrimSpace(elem), "NULL") {
+			elems = append(elems, nil)
+		} else {
+			if !quoted {
+				elem = strings.TrimSpace(elem)
+			}
+			jb, _ := json.Marshal(elem)
+			elems = append(elems, jb)
+		}
+
+		if i >= len(str) {
+			break
+		}
+		if str[i] != ',' {
+			return nil, fmt.Errorf("goloquent: invalid array value %q", b2s(b))
+		}
+	}
+	return elems, nil
+}
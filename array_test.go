@@ -0,0 +1,181 @@
+package goloquent
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+func TestParseArray(t *testing.T) {
+	elems, err := parseArray([]byte(`{abc,"a,b","say \"hi\"",NULL,"NULL",""}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{`"abc"`, `"a,b"`, `"say \"hi\""`, "", `"NULL"`, `""`}
+	if len(elems) != len(expected) {
+		t.Fatalf(errUnexpectedResult, "parseArray")
+	}
+	for i := range elems {
+		if string(elems[i]) != expected[i] {
+			t.Fatalf("unexpected array element %s, expected %s", elems[i], expected[i])
+		}
+	}
+	if elems, err := parseArray([]byte("{}")); err != nil || len(elems) != 0 {
+		t.Fatalf(errUnexpectedResult, "parseArray")
+	}
+	for _, str := range []string{`{"abc}`, `{{1,2},{3,4}}`, `[1,2]`} {
+		if _, err := parseArray([]byte(str)); err == nil {
+			t.Fatalf("invalid array %q should return error", str)
+		}
+	}
+
+	str, err := formatArray([]interface{}{"a,b", `say "hi"`, nil, int64(1), true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if str != `{"a,b","say \"hi\"",NULL,1,true}` {
+		t.Fatalf(errUnexpectedResult, "formatArray")
+	}
+}
+
+type testMember struct {
+	Key       *datastore.Key `goloquent:"__key__"`
+	Nicknames []string       `goloquent:",array"`
+	Scores    []int64        `goloquent:",array,noindex"`
+	Rates     []Decimal      `goloquent:",array"`
+	Tags      []string
+}
+
+func TestNativeArray(t *testing.T) {
+	codec, err := getStructCodec(&testMember{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][2]string{
+		"Nicknames": {"json", "text[]"},
+		"Scores":    {"json", "bigint[]"},
+		"Rates":     {"json", "numeric[]"},
+		"Tags":      {"json", "jsonb"},
+	}
+	for _, c := range getColumns(nil, codec) {
+		e, isOk := expected[c.Name()]
+		if !isOk {
+			continue
+		}
+		if sc := new(sequel).GetSchema(c)[0]; sc.DataType != e[0] {
+			t.Fatalf("unexpected mysql schema of %q, %s", c.Name(), sc.DataType)
+		}
+		sc := new(postgres).GetSchema(c)[0]
+		if sc.DataType != e[1] {
+			t.Fatalf("unexpected postgres schema of %q, %s", c.Name(), sc.DataType)
+		}
+		if sc.isArray() && sc.IsIndexed != (c.Name() != "Scores") {
+			t.Fatalf("unexpected index of %q", c.Name())
+		}
+	}
+	if new(postgres).indexColumn(Schema{Name: "Nicknames", DataType: "text[]"}) != `USING GIN ("Nicknames")` {
+		t.Fatalf(errUnexpectedResult, "indexColumn")
+	}
+
+	db := newFakeDB(t, new(postgres))
+	props, err := SaveStruct(&testMember{
+		Nicknames: []string{"Sam", `"Sammy"`},
+		Rates:     []Decimal{MustDecimal("1.50")},
+		Tags:      []string{"a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := newBuilder(db.NewQuery())
+	if v, _ := b.propertyValue(props["Nicknames"]); v != `{"Sam","\"Sammy\""}` {
+		t.Fatalf(errUnexpectedResult, "propertyValue")
+	}
+	if v, _ := b.propertyValue(props["Rates"]); v != `{"1.50"}` {
+		t.Fatalf(errUnexpectedResult, "propertyValue")
+	}
+	if v, _ := b.propertyValue(props["Tags"]); v != `["a"]` {
+		t.Fatalf(errUnexpectedResult, "propertyValue")
+	}
+
+	it := &Iterator{
+		columns: []string{keyFieldName, "Nicknames", "Scores", "Rates", "Tags"},
+		results: []map[string][]byte{{
+			"Nicknames": []byte(`{Sam,"\"Sammy\""}`),
+			"Scores":    []byte(`{1,2}`),
+			"Rates":     []byte(`{1.50}`),
+			"Tags":      []byte(`["a"]`),
+		}},
+	}
+	var p testMember
+	if _, err := it.scan(&p); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p.Nicknames, []string{"Sam", `"Sammy"`}) || !reflect.DeepEqual(p.Scores, []int64{1, 2}) ||
+		len(p.Rates) != 1 || p.Rates[0].String() != "1.50" || !reflect.DeepEqual(p.Tags, []string{"a"}) {
+		t.Fatalf(errUnexpectedResult, "Iterator.scan")
+	}
+}
+
+func TestArrayFilter(t *testing.T) {
+	checks := []struct {
+		dialect Dialect
+		query   func(q *Query) *Query
+		raw     string
+		args    []interface{}
+	}{
+		{new(postgres), func(q *Query) *Query { return q.WhereContains("Nicknames", []string{"a", "b"}) },
+			`"Nicknames" @> $1`, []interface{}{`{"a","b"}`}},
+		{new(postgres), func(q *Query) *Query { return q.WhereOverlaps("Scores", []int{1, 2}) },
+			`"Scores" && $1`, []interface{}{`{1,2}`}},
+		{new(postgres), func(q *Query) *Query { return q.WhereContainedBy("Nicknames", "a") },
+			`"Nicknames" <@ $1`, []interface{}{`{"a"}`}},
+		{new(postgres), func(q *Query) *Query { return q.WhereContains("Tags", []string{"a", "b"}) },
+			`"Tags" @> $1::jsonb`, []interface{}{`["a","b"]`}},
+		{new(postgres), func(q *Query) *Query { return q.WhereOverlaps("Tags", []string{"a", "b"}) },
+			`EXISTS (SELECT 1 FROM jsonb_array_elements($1::jsonb) AS e WHERE "Tags" @> jsonb_build_array(e.value))`, []interface{}{`["a","b"]`}},
+		{new(postgres), func(q *Query) *Query { return q.WhereContainedBy("Tags", "a") },
+			`"Tags" <@ $1::jsonb`, []interface{}{`["a"]`}},
+		{new(sequel), func(q *Query) *Query { return q.WhereContains("Nicknames", []string{"a", "b"}) },
+			"JSON_CONTAINS(`Nicknames`, ?)", []interface{}{`["a","b"]`}},
+		{new(sequel), func(q *Query) *Query { return q.WhereOverlaps("Scores", []int{1, 2}) },
+			"JSON_OVERLAPS(`Scores`, ?)", []interface{}{`[1,2]`}},
+		{new(sequel), func(q *Query) *Query { return q.WhereContainedBy("Nicknames", "a") },
+			"JSON_CONTAINS(?, `Nicknames`)", []interface{}{`["a"]`}},
+	}
+	for _, c := range checks {
+		db := newFakeDB(t, c.dialect)
+		s, err := c.query(db.Table("Profile").newQuery()).ToSQL(OpGet, &[]testMember{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(s.Arguments(), c.args) || !strings.Contains(s.Raw(), c.raw) {
+			t.Fatalf("unexpected statement %s %v", s.Raw(), s.Arguments())
+		}
+	}
+
+	db := newFakeDB(t)
+	if _, err := db.WhereContains("Nicknames", nil).ToSQL(OpGet, &[]testMember{}); err == nil {
+		t.Fatal("nil value of array filter should return error")
+	}
+}
+
+func TestConvertArrayColumn(t *testing.T) {
+	dialect := new(postgres)
+	newFakeDB(t, dialect)
+	fakeMu.Lock()
+	fakeStatements = fakeStatements[:0]
+	fakeMu.Unlock()
+	if err := dialect.convertArrayColumn("Member", Schema{Name: "Nicknames", DataType: "text[]"}); err != nil {
+		t.Fatal(err)
+	}
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	if len(fakeStatements) != 4 || !strings.HasSuffix(fakeStatements[0], `"Member" ADD COLUMN "Nicknames_array" text[] NULL;`) ||
+		!strings.Contains(fakeStatements[1], `THEN ARRAY(SELECT jsonb_array_elements_text("Nicknames"))::text[] ELSE '{}' END;`) ||
+		!strings.HasSuffix(fakeStatements[2], `"Member" DROP COLUMN "Nicknames";`) ||
+		!strings.HasSuffix(fakeStatements[3], `"Member" RENAME COLUMN "Nicknames_array" TO "Nicknames";`) {
+		t.Fatalf("unexpected statements %v", fakeStatements)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/datastore"
@@ -19,7 +20,8 @@ import (
 
 const (
 	variable      = "??"
-	jsonDelimeter = ":"
+	jsonDelimeter = ">"
+	maxMultiKeys  = 500
 )
 
 type index int
@@ -32,6 +34,8 @@ const (
 type builder struct {
 	db    *DB
 	query scope
+	// entity is the model of the statement, it's nil when the model is unknown, eg. `Flush`
+	entity *entity
 }
 
 func newBuilder(query *Query) *builder {
@@ -62,15 +66,81 @@ func (b *builder) addIndex(fields []string, idx index) error {
 		b.db.dialect.Quote(strings.Join(fields, ","))))
 	return b.db.client.execStmt(&stmt{
 		statement: buf,
+		crud:      OpMigrate,
+		table:     table,
 	})
 }
 
+func (b *builder) addJSONIndex(table, field, path string) error {
+	field, path = strings.TrimSpace(field), strings.Trim(strings.TrimSpace(path), ".")
+	if field == "" || path == "" {
+		return fmt.Errorf("goloquent: json index field and path cannot be empty")
+	}
+	d, isOk := b.db.dialect.(JSONIndexDialect)
+	if !isOk {
+		return errUnsupported(b.db.dialect, "json index")
+	}
+	return d.AddJSONIndex(table, field, path)
+}
+
+// migrateJSONIndexes will index the json paths which declared in struct tag, eg. `goloquent:",index=region.regionCode"`
+func (b *builder) migrateJSONIndexes(e *entity) error {
+	for _, c := range e.columns {
+		paths := c.field.JSONIndexes()
+		if len(paths) <= 0 {
+			continue
+		}
+		for _, ss := range b.db.dialect.GetSchema(c) {
+			if !strings.HasPrefix(ss.DataType, "json") {
+				return fmt.Errorf("goloquent: json index is not applicable for column %q", c.Name())
+			}
+		}
+		for _, path := range paths {
+			if err := b.addJSONIndex(e.Name(), c.Name(), path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// migrateFullTextIndexes will create the full text index of the fields with `fulltext` option
+func (b *builder) migrateFullTextIndexes(e *entity) error {
+	for _, c := range e.columns {
+		if !c.field.IsFullText() {
+			continue
+		}
+		d, isOk := b.db.dialect.(FullTextDialect)
+		if !isOk {
+			return errUnsupported(b.db.dialect, "full text index")
+		}
+		if err := d.AddFullTextIndex(e.Name(), c.Name(), c.field.FullTextLanguage()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (b *builder) dropTableIfExists(table string) error {
 	buf := new(bytes.Buffer)
 	buf.WriteString(fmt.Sprintf("DROP TABLE IF EXISTS %s;", b.db.dialect.GetTable(table)))
-	return b.db.client.execStmt(&stmt{
+	if err := b.execWrite(OpDrop, &stmt{
 		statement: buf,
-	})
+	}, table); err != nil {
+		return err
+	}
+	b.db.resetColumns(table)
+	b.db.invalidateEntities(table)
+	return nil
+}
+
+// fieldOf will return the field of the column from the model, it's not found when the model is unknown
+func (b *builder) fieldOf(name string) (field, bool) {
+	if b.entity == nil {
+		return field{}, false
+	}
+	c, isOk := b.entity.fields[name]
+	return c.field, isOk
 }
 
 func (b *builder) quoteIfNecessary(v string) string {
@@ -86,6 +156,10 @@ func (b *builder) buildSelect(query scope) *stmt {
 		projection := make([]string, len(query.projection), len(query.projection))
 		copy(projection, query.projection)
 		for i := 0; i < len(query.projection); i++ {
+			if qualifier, field := query.splitField(projection[i]); qualifier != "" {
+				projection[i] = b.quoteColumn(qualifier, field)
+				continue
+			}
 			projection[i] = b.quoteIfNecessary(projection[i])
 		}
 		scope = strings.Join(projection, ",")
@@ -112,7 +186,62 @@ func (b *builder) buildWhere(query scope) (*stmt, error) {
 	args := make([]interface{}, 0)
 
 	for _, f := range query.filters {
-		name := b.db.dialect.Quote(f.Field())
+		qualifier, field := query.splitField(f.Field())
+		name := b.quoteColumn(qualifier, field)
+		if f.operator == Near || f.operator == WithinBox {
+			d, isOk := b.db.dialect.(GeoDialect)
+			if !isOk {
+				return nil, errUnsupported(b.db.dialect, "geo filter")
+			}
+			str, vv, err := d.FilterGeo(f)
+			if err != nil {
+				return nil, err
+			}
+			wheres = append(wheres, str)
+			args = append(args, vv...)
+			continue
+		}
+		if f.operator == Contains || f.operator == Overlaps || f.operator == ContainedBy {
+			if qualifier == "" || qualifier == query.base() {
+				fd, isOk := b.fieldOf(field)
+				f.isNative = isOk && isNativeArray(fd.typeOf, fd.tag)
+			}
+			d, isOk := b.db.dialect.(ArrayDialect)
+			if !isOk {
+				return nil, errUnsupported(b.db.dialect, "array filter")
+			}
+			str, vv, err := d.FilterArray(f)
+			if err != nil {
+				return nil, err
+			}
+			wheres = append(wheres, str)
+			args = append(args, vv...)
+			continue
+		}
+		if f.operator == MatchAgainst {
+			vi, isOk := f.value.(fullTextSearch)
+			if !isOk {
+				vi = fullTextSearch{fields: []string{f.Field()}, query: fmt.Sprintf("%v", f.value)}
+			}
+			// the individual full text index is only migrated for the field with `fulltext` option
+			for _, field := range vi.fields {
+				if fd, isOk := b.fieldOf(field); !isOk || !fd.IsFullText() {
+					vi.perField = false
+				}
+			}
+			f.value = vi
+			d, isOk := b.db.dialect.(FullTextDialect)
+			if !isOk {
+				return nil, errUnsupported(b.db.dialect, "full text search")
+			}
+			str, vv, err := d.FilterFullText(f)
+			if err != nil {
+				return nil, err
+			}
+			wheres = append(wheres, str)
+			args = append(args, vv...)
+			continue
+		}
 
 		var v interface{}
 		switch vi := f.value.(type) {
@@ -122,7 +251,8 @@ func (b *builder) buildWhere(query scope) (*stmt, error) {
 			subQuery.WriteString(b.buildSelect(vi.scope).string())
 			subQuery.WriteString(" FROM ")
 			subQuery.WriteString(b.db.dialect.GetTable(vi.scope.table))
-			stmt, err := b.buildStmt(vi.scope)
+			// the fields of sub query are not belongs to the model
+			stmt, err := (&builder{db: b.db, query: vi.scope}).buildStmt(vi.scope)
 			if err != nil {
 				return nil, fmt.Errorf("goloquent: %v", err)
 			}
@@ -138,6 +268,10 @@ func (b *builder) buildWhere(query scope) (*stmt, error) {
 			}
 
 			if f.IsJSON() {
+				if paths := strings.SplitN(field, jsonDelimeter, 2); len(paths) > 1 && (qualifier == "" || qualifier == query.base()) {
+					fd, _ := b.fieldOf(strings.TrimSpace(paths[0]))
+					f.isIndexed = newDictionary(fd.JSONIndexes()).has(strings.TrimSpace(paths[1]))
+				}
 				str, vv, err := b.db.dialect.FilterJSON(f)
 				if err != nil {
 					return nil, fmt.Errorf("goloquent: %w", err)
@@ -147,9 +281,25 @@ func (b *builder) buildWhere(query scope) (*stmt, error) {
 				continue
 			}
 
-			switch f.Field() {
+			// filter is using the precision of column, otherwise the equality of `datetime` will never match,
+			// the precision of `TimeConfig` is used when the model is unknown
+			if t, isOk := timeOf(f.value); isOk {
+				var tg tag
+				if qualifier == "" || qualifier == query.base() {
+					fd, _ := b.fieldOf(field)
+					tg = fd.tag
+				}
+				if d, isOk := b.db.dialect.(TimeDialect); isOk {
+					vi = d.TimeValue(t, b.db.client.timeConfig().precision(tg))
+				}
+			}
+
+			switch field {
 			case keyFieldName, pkColumn:
-				name = b.db.dialect.Quote(pkColumn)
+				name = b.quoteColumn(qualifier, pkColumn)
+				if qualifier == "" {
+					name = b.baseColumn(query, pkColumn)
+				}
 				vi, err = interfaceToKeyString(f.value)
 				if err != nil {
 					return nil, err
@@ -159,6 +309,9 @@ func (b *builder) buildWhere(query scope) (*stmt, error) {
 		}
 
 		op, vv := "=", variable
+		if isDecimalValue(f.value) {
+			vv = decimalVariable
+		}
 		switch f.operator {
 		case Equal:
 			if v == nil {
@@ -216,7 +369,7 @@ func (b *builder) buildWhere(query scope) (*stmt, error) {
 					return nil, fmt.Errorf(`goloquent: value for "In" operator cannot be empty`)
 				}
 				vv = fmt.Sprintf("(%s)", strings.TrimRight(
-					strings.Repeat(variable+",", len(x)), ","))
+					strings.Repeat(vv+",", len(x)), ","))
 				wheres = append(wheres, fmt.Sprintf("%s %s %s", name, op, vv))
 				args = append(args, x...)
 				continue
@@ -237,7 +390,7 @@ func (b *builder) buildWhere(query scope) (*stmt, error) {
 					return nil, fmt.Errorf(`goloquent: value for "NotIn" operator cannot be empty`)
 				}
 				vv = fmt.Sprintf("(%s)", strings.TrimRight(
-					strings.Repeat(variable+",", len(x)), ","))
+					strings.Repeat(vv+",", len(x)), ","))
 				wheres = append(wheres, fmt.Sprintf("%s %s %s", name, op, vv))
 				args = append(args, x...)
 				continue
@@ -245,21 +398,6 @@ func (b *builder) buildWhere(query scope) (*stmt, error) {
 				wheres = append(wheres, fmt.Sprintf("%s %s %s", name, op, vi))
 				continue
 			}
-		case MatchAgainst:
-			if f.raw != "" {
-				wheres = append(wheres, f.raw)
-			} else {
-				wheres = append(wheres, fmt.Sprintf("MATCH(%s) AGAINST(%s)", name, variable))
-
-			}
-			switch vi := v.(type) {
-			case []interface{}:
-				args = append(args, vi...)
-			case nil:
-			default:
-				args = append(args, v)
-			}
-			continue
 		}
 		wheres = append(wheres, fmt.Sprintf("%s %s %s", name, op, vv))
 		args = append(args, v)
@@ -270,8 +408,12 @@ func (b *builder) buildWhere(query scope) (*stmt, error) {
 			buf := new(bytes.Buffer)
 			buf.WriteByte('(')
 			for _, x := range aa.data {
-				buf.WriteString(fmt.Sprintf("%s LIKE %s OR ", b.db.dialect.Quote(pkColumn), variable))
-				args = append(args, fmt.Sprintf("%%%s/%%", stringifyKey(x.(*datastore.Key))))
+				w, arg, err := b.buildDescendant(query, x.(*datastore.Key), aa.depth)
+				if err != nil {
+					return nil, err
+				}
+				buf.WriteString(w + " OR ")
+				args = append(args, arg...)
 			}
 			buf.Truncate(buf.Len() - 4)
 			buf.WriteByte(')')
@@ -279,8 +421,12 @@ func (b *builder) buildWhere(query scope) (*stmt, error) {
 			continue
 		}
 
-		wheres = append(wheres, b.db.dialect.Quote(pkColumn)+" LIKE "+variable)
-		args = append(args, fmt.Sprintf("%%%s/%%", stringifyKey(aa.data[0].(*datastore.Key))))
+		w, arg, err := b.buildDescendant(query, aa.data[0].(*datastore.Key), aa.depth)
+		if err != nil {
+			return nil, err
+		}
+		wheres = append(wheres, w)
+		args = append(args, arg...)
 	}
 
 	if len(wheres) > 0 {
@@ -296,6 +442,39 @@ func (b *builder) buildWhere(query scope) (*stmt, error) {
 	}, nil
 }
 
+// buildDescendant will match the entities under the ancestor key using the `$Parent` column,
+// both the equality and prefix matching are able to use the index, the depth limit is only
+// evaluated on the rows within the prefix range.
+// When the table is not migrated yet, the ancestor is matched by `$Key` like before.
+func (b *builder) buildDescendant(query scope, k *datastore.Key, depth int) (string, []interface{}, error) {
+	anc := stringifyKey(k)
+	table := query.table
+	if table == "" && b.entity != nil {
+		table = b.entity.Name()
+	}
+	if !b.hasParent(table) {
+		if depth > 0 {
+			return "", nil, fmt.Errorf("goloquent: table %q has no %s column, it must be migrated to query descendants with depth", table, parentColumn)
+		}
+		return fmt.Sprintf("%s LIKE %s", b.baseColumn(query, pkColumn), variable),
+			[]interface{}{fmt.Sprintf("%%%s/%%", anc)}, nil
+	}
+	name := b.baseColumn(query, parentColumn)
+	if depth == 1 {
+		return fmt.Sprintf("%s = %s", name, variable), []interface{}{anc}, nil
+	}
+	buf := new(bytes.Buffer)
+	args := []interface{}{anc, escapeLike(anc+keyDelimeter) + "%"}
+	buf.WriteString(fmt.Sprintf("(%s = %s OR (%s LIKE %s", name, variable, name, variable))
+	if depth > 1 {
+		buf.WriteString(fmt.Sprintf(" AND LENGTH(%s) - LENGTH(REPLACE(%s, '%s', '')) <= %s",
+			name, name, keyDelimeter, variable))
+		args = append(args, strings.Count(anc, keyDelimeter)+depth-1)
+	}
+	buf.WriteString("))")
+	return buf.String(), args, nil
+}
+
 func (b *builder) buildOrderBy(query scope) (*stmt, error) {
 	buf := new(bytes.Buffer)
 
@@ -308,6 +487,64 @@ func (b *builder) buildOrderBy(query scope) (*stmt, error) {
 			if i > 0 {
 				buf.WriteByte(',')
 			}
+			if x, isOk := o.(expr.Distance); isOk {
+				d, isOk := b.db.dialect.(GeoDialect)
+				if !isOk {
+					return nil, errUnsupported(b.db.dialect, "geo distance")
+				}
+				str, vals := d.GeoDistance(x.Name, datastore.GeoPoint{Lat: x.Lat, Lng: x.Lng})
+				buf.WriteString(str)
+				if x.Direction == expr.Descending {
+					buf.WriteString(" DESC")
+				}
+				args = append(args, vals...)
+				continue
+			}
+			if x, isOk := o.(expr.Relevance); isOk {
+				// same as the filter, the individual full text index is only migrated for the field with `fulltext` option
+				perField := true
+				for _, field := range x.Fields {
+					if fd, isOk := b.fieldOf(field); !isOk || !fd.IsFullText() {
+						perField = false
+					}
+				}
+				// the relevance must be ranked with the same query as the filter, otherwise the operators are ignored
+				mode := NaturalLanguageMode
+				if x.Boolean {
+					mode = BooleanMode
+				}
+				for _, f := range query.filters {
+					if vi, isOk := f.value.(fullTextSearch); isOk && f.operator == MatchAgainst && vi.query == x.Query {
+						mode = vi.mode
+					}
+				}
+				d, isOk := b.db.dialect.(FullTextDialect)
+				if !isOk {
+					return nil, errUnsupported(b.db.dialect, "full text relevance")
+				}
+				str, vals, err := d.FullTextRelevance(x.Fields, x.Query, x.Language, mode, perField)
+				if err != nil {
+					return nil, err
+				}
+				buf.WriteString(str)
+				if x.Direction == expr.Descending {
+					buf.WriteString(" DESC")
+				}
+				args = append(args, vals...)
+				continue
+			}
+			if x, isOk := o.(expr.Sort); isOk {
+				if qualifier, field := query.splitField(x.Name); qualifier != "" {
+					if field == keyFieldName {
+						field = pkColumn
+					}
+					buf.WriteString(b.quoteColumn(qualifier, field))
+					if x.Direction == expr.Descending {
+						buf.WriteString(" DESC")
+					}
+					continue
+				}
+			}
 			vals, err := stmtRegistry.BuildStatement(buf, reflect.ValueOf(o))
 			if err != nil {
 				return nil, err
@@ -372,10 +609,26 @@ func (b *builder) migrate(model interface{}) error {
 		return err
 	}
 	e.setName(b.query.table)
+	if e.typeOf == typeOfPropertyList && e.Name() == typeOfPropertyList.Name() {
+		return fmt.Errorf("goloquent: missing table name for datastore.PropertyList")
+	}
 	if b.db.dialect.HasTable(e.Name()) {
-		return b.alterTable(e)
+		err = b.alterTable(e)
+	} else {
+		err = b.createTable(e)
+	}
+	if err != nil {
+		return err
+	}
+	// the cached statements may be prepared with the previous schema
+	b.db.client.stmts.purge()
+	b.db.resetColumns(e.Name())
+	b.db.invalidate(e.Name())
+	b.db.invalidateEntities(e.Name())
+	if err := b.migrateJSONIndexes(e); err != nil {
+		return err
 	}
-	return b.createTable(e)
+	return b.migrateFullTextIndexes(e)
 }
 
 func (b *builder) migrateMultiple(models []interface{}) error {
@@ -388,10 +641,17 @@ func (b *builder) migrateMultiple(models []interface{}) error {
 }
 
 func (b *builder) getCommand(e *entity) (*stmt, error) {
+	if err := b.resolveDynamic(e); err != nil {
+		return nil, err
+	}
+	b.entity = e
 	query := b.query
 	buf := new(bytes.Buffer)
 	buf.WriteString(b.buildSelect(query).string())
 	buf.WriteString(" FROM " + b.db.dialect.GetTable(e.Name()))
+	if query.alias != "" {
+		buf.WriteString(" " + b.db.dialect.Quote(query.alias))
+	}
 	if !query.noScope && e.hasSoftDelete() {
 		query.filters = append(query.filters, Filter{
 			field:    softDeleteColumn,
@@ -419,42 +679,138 @@ func (b *builder) getCommand(e *entity) (*stmt, error) {
 }
 
 func (b *builder) run(table string, cmd *stmt) (*Iterator, error) {
-	var rows, err = b.db.client.execQuery(cmd)
+	// the result cache is never used in transaction, it may not be committed
+	var cacheKey string
+	if b.query.cacheTTL > 0 && b.db.cache != nil && !b.db.inTransaction() {
+		cacheKey = b.db.resultCacheKey(&Stmt{stmt: *cmd, replacer: b.db.dialect}, queryTables(b.query))
+		if v, isOk := b.db.cache.Get(cacheKey); isOk {
+			it := &Iterator{
+				table:    table,
+				stmt:     &Stmt{stmt: *cmd, replacer: b.db.dialect},
+				position: -1,
+				timeConf: b.db.client.timeConfig(),
+			}
+			if _, err := decodeResult(v, it); err == nil {
+				return it, nil
+			}
+		}
+	}
+
+	if cmd.crud == "" {
+		cmd.crud = OpGet
+	}
+	if cmd.table == "" {
+		cmd.table = table
+	}
+	var rows, ss, err = b.db.client.execQuery(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("goloquent: %v", err)
 	}
 	defer rows.Close()
+	it, err := b.newIterator(table, cmd, rows)
+	if err != nil {
+		b.db.client.logRows(ss, 0, err)
+		return nil, err
+	}
+
+	i := 0
+	for rows.Next() {
+		if err := it.readRow(rows, i); err != nil {
+			b.db.client.logRows(ss, int64(i), err)
+			return nil, err
+		}
+		it.patchKey()
+		i++
+	}
+	b.db.client.logRows(ss, int64(i), rows.Err())
+
+	if cacheKey != "" {
+		if v, err := encodeResult(it, ""); err == nil {
+			b.db.cache.Set(cacheKey, v, b.query.cacheTTL)
+		}
+	}
+	return it, nil
+}
+
+// execWrite will execute the statement which mutate the tables, the result cache of the tables is invalidated
+func (b *builder) execWrite(op Operation, cmd *stmt, tables ...string) error {
+	cmd.crud = op
+	if len(tables) > 0 {
+		cmd.table = tables[0]
+	}
+	if err := b.db.client.execStmt(cmd); err != nil {
+		return err
+	}
+	b.db.invalidate(tables...)
+	return nil
+}
+
+func (b *builder) newIterator(table string, cmd *stmt, rows *sql.Rows) (*Iterator, error) {
 	cols, err := rows.Columns()
 	if err != nil {
 		return nil, fmt.Errorf("goloquent: %v", err)
 	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("goloquent: %v", err)
+	}
 
 	it := Iterator{
 		table:    table,
 		stmt:     &Stmt{stmt: *cmd, replacer: b.db.dialect},
 		position: -1,
 		columns:  cols,
+		types:    make(map[string]string),
+		timeConf: b.db.client.timeConfig(),
 	}
+	for _, ct := range colTypes {
+		it.types[ct.Name()] = ct.DatabaseTypeName()
+	}
+	return &it, nil
+}
 
-	i := 0
-	for rows.Next() {
-		m := make([]interface{}, len(cols))
-		for j := range cols {
-			m[j] = &m[j]
-		}
+func (b *builder) keysOnly() (*Iterator, error) {
+	query := b.query
+	table := query.table
+	if table == "" {
+		return nil, fmt.Errorf("goloquent: unable to perform keys only query without table name")
+	}
+	if !query.noScope && b.hasSoftDelete(table) {
+		query.filters = append(query.filters, Filter{
+			field:    softDeleteColumn,
+			operator: Equal,
+			value:    nil,
+		})
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteString("SELECT " + b.db.dialect.Quote(pkColumn))
+	buf.WriteString(" FROM " + b.db.dialect.GetTable(table))
+	cmd, err := b.buildStmt(query)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(cmd.string())
+	buf.WriteString(";")
+	return b.run(table, &stmt{
+		statement: buf,
+		arguments: cmd.arguments,
+	})
+}
 
-		if err := rows.Scan(m...); err != nil {
+func (b *builder) getKeys() ([]*datastore.Key, error) {
+	it, err := b.keysOnly()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*datastore.Key, 0, it.Count())
+	for it.Next() {
+		k, err := it.Key()
+		if err != nil {
 			return nil, err
 		}
-
-		for j, name := range cols {
-			it.put(i, name, m[j])
-		}
-		it.patchKey()
-		i++
+		keys = append(keys, k)
 	}
-
-	return &it, nil
+	return keys, nil
 }
 
 func (b *builder) get(model interface{}, mustExist bool) error {
@@ -472,6 +828,7 @@ func (b *builder) get(model interface{}, mustExist bool) error {
 	if err != nil {
 		return err
 	}
+	e.setTypes(it)
 
 	first := it.First()
 	if mustExist && first == nil {
@@ -506,6 +863,7 @@ func (b *builder) getMulti(model interface{}) error {
 	if err != nil {
 		return err
 	}
+	e.setTypes(it)
 
 	v := reflect.Indirect(reflect.ValueOf(model))
 	vv := reflect.MakeSlice(v.Type(), 0, 0)
@@ -525,6 +883,125 @@ func (b *builder) getMulti(model interface{}) error {
 	return nil
 }
 
+func (b *builder) getByKeys(keys []*datastore.Key, model interface{}) error {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	isPtr, t := checkMultiPtr(v)
+	vv := reflect.MakeSlice(v.Type(), len(keys), len(keys))
+
+	errs := make(MultiError, len(keys))
+	useCache := b.useEntityCache(t)
+	tables := make([]string, 0)
+	groups := make(map[string][]int)
+	for i, k := range keys {
+		if k == nil || k.Incomplete() {
+			errs[i] = fmt.Errorf("goloquent: invalid key value, %v", k)
+			continue
+		}
+		table := k.Kind
+		if b.query.table != "" {
+			table = b.query.table
+		}
+		if _, isExist := groups[table]; !isExist {
+			tables = append(tables, table)
+		}
+		groups[table] = append(groups[table], i)
+	}
+
+	for _, table := range tables {
+		pos := groups[table]
+		for len(pos) > 0 {
+			chunk := pos
+			if len(chunk) > maxMultiKeys {
+				chunk = pos[:maxMultiKeys]
+			}
+			pos = pos[len(chunk):]
+
+			idx := make(map[string][]int)
+			kk := make([]*datastore.Key, 0, len(chunk))
+			for _, i := range chunk {
+				pk := stringPk(keys[i])
+				if useCache {
+					if it := b.loadEntity(table, keys[i], t); it != nil {
+						vi := reflect.New(t)
+						if _, err := it.scan(vi.Interface()); err != nil {
+							return err
+						}
+						if !isPtr {
+							vi = vi.Elem()
+						}
+						vv.Index(i).Set(vi)
+						continue
+					}
+				}
+				if _, isExist := idx[pk]; !isExist {
+					kk = append(kk, keys[i])
+				}
+				idx[pk] = append(idx[pk], i)
+			}
+			if len(kk) <= 0 {
+				continue
+			}
+
+			e, err := newEntity(reflect.New(t).Interface())
+			if err != nil {
+				return err
+			}
+			e.setName(table)
+			query := b.query
+			query.limit, query.offset = -1, -1
+			query.orders = nil
+			query.filters = append(append(make([]Filter, 0), query.filters...), Filter{
+				field:    keyFieldName,
+				operator: In,
+				value:    kk,
+			})
+			cmd, err := (&builder{db: b.db, query: query}).getCommand(e)
+			if err != nil {
+				return err
+			}
+			it, err := b.run(table, cmd)
+			if err != nil {
+				return err
+			}
+
+			for it.Next() {
+				k, err := it.Key()
+				if err != nil {
+					return err
+				}
+				if useCache {
+					b.storeEntity(table, k, t, it)
+				}
+				vi := reflect.New(t)
+				if _, err := it.scan(vi.Interface()); err != nil {
+					return err
+				}
+				if !isPtr {
+					vi = vi.Elem()
+				}
+				for _, i := range idx[stringPk(k)] {
+					vv.Index(i).Set(vi)
+				}
+				delete(idx, stringPk(k))
+			}
+
+			for _, ii := range idx {
+				for _, i := range ii {
+					errs[i] = ErrNoSuchEntity
+				}
+			}
+		}
+	}
+
+	v.Set(vv)
+	for _, err := range errs {
+		if err != nil {
+			return errs
+		}
+	}
+	return nil
+}
+
 func baseToInterface(it interface{}) interface{} {
 	var v interface{}
 	switch vi := it.(type) {
@@ -533,7 +1010,7 @@ func baseToInterface(it interface{}) interface{} {
 	case []byte:
 		v = string(vi)
 	case time.Time:
-		v = vi.Format("2006-01-02 15:04:05")
+		v = vi.UTC().Format(timeLayout)
 	default:
 		v = vi
 	}
@@ -675,12 +1152,32 @@ func (b *builder) paginate(p *Pagination, model interface{}) error {
 	return nil
 }
 
+// copyColumns will list the columns of the source table explicitly when there is no projection,
+// the generated column of json index is excluded because it's not writable
+func (b *builder) copyColumns() string {
+	if len(b.query.projection) > 0 || len(b.query.distinctOn) > 0 {
+		return ""
+	}
+	cols := make([]string, 0)
+	for _, c := range b.db.dialect.GetColumns(b.query.table) {
+		if isJSONPathColumn(c) {
+			continue
+		}
+		cols = append(cols, b.db.dialect.Quote(c))
+	}
+	return strings.Join(cols, ",")
+}
+
 func (b *builder) replaceInto(table string) error {
 	buf, args := new(bytes.Buffer), make([]interface{}, 0)
 	buf.WriteString("REPLACE INTO ")
 	buf.WriteString(b.db.dialect.GetTable(table))
 	buf.WriteString(" ")
 	cmd := b.buildSelect(b.query)
+	if cols := b.copyColumns(); cols != "" {
+		buf.WriteString("(" + cols + ") ")
+		cmd = &stmt{statement: bytes.NewBufferString("SELECT " + cols)}
+	}
 	buf.WriteString(cmd.string())
 	buf.WriteString(" FROM " + b.db.dialect.GetTable(b.query.table))
 	cmd, err := b.buildWhere(b.query)
@@ -696,10 +1193,14 @@ func (b *builder) replaceInto(table string) error {
 	args = append(args, ss.arguments...)
 	buf.WriteString(b.buildLimitOffset(b.query).string())
 	buf.WriteString(";")
-	return b.db.client.execStmt(&stmt{
+	if err := b.execWrite(OpUpsert, &stmt{
 		statement: buf,
 		arguments: args,
-	})
+	}, table); err != nil {
+		return err
+	}
+	b.db.invalidateEntities(table)
+	return nil
 }
 
 func (b *builder) insertInto(table string) error {
@@ -708,6 +1209,10 @@ func (b *builder) insertInto(table string) error {
 	buf.WriteString(b.db.dialect.GetTable(table))
 	buf.WriteString(" ")
 	cmd := b.buildSelect(b.query)
+	if cols := b.copyColumns(); cols != "" {
+		buf.WriteString("(" + cols + ") ")
+		cmd = &stmt{statement: bytes.NewBufferString("SELECT " + cols)}
+	}
 	buf.WriteString(cmd.string())
 	buf.WriteString(" FROM " + b.db.dialect.GetTable(b.query.table))
 	cmd, err := b.buildWhere(b.query)
@@ -719,10 +1224,46 @@ func (b *builder) insertInto(table string) error {
 		args = append(args, cmd.arguments...)
 	}
 	buf.WriteString(";")
-	return b.db.client.execStmt(&stmt{
+	if err := b.execWrite(OpCreate, &stmt{
 		statement: buf,
 		arguments: args,
-	})
+	}, table); err != nil {
+		return err
+	}
+	b.db.invalidateEntities(table)
+	return nil
+}
+
+// propertyValue will convert the property to the value which accepted by the dialect
+func (b *builder) propertyValue(p Property) (interface{}, error) {
+	// same as datastore, the zero value of `omitempty` field of datastore tag is not stored
+	if p.tag.isNullEmpty() && p.isZero() {
+		return nil, nil
+	}
+	if err := validateEnum(p); err != nil {
+		return nil, err
+	}
+	if g, isOk := geoPointOf(p.Value); isOk {
+		if d, isOk := b.db.dialect.(GeoDialect); isOk {
+			return d.GeoValue(g), nil
+		}
+	}
+	if t, isOk := timeOf(p.Value); isOk {
+		if d, isOk := b.db.dialect.(TimeDialect); isOk {
+			return d.TimeValue(t, b.db.client.timeConfig().precision(p.tag)), nil
+		}
+	}
+	if d, isOk := b.db.dialect.(ArrayDialect); isOk && isNativeArray(p.typeOf, p.tag) {
+		vi, err := interfaceToValue(p.Value)
+		if err != nil {
+			return nil, err
+		}
+		if x, isOk := vi.([]interface{}); isOk {
+			return d.ArrayValue(x)
+		}
+		return vi, nil
+	}
+	return p.Interface()
 }
 
 func (b *builder) putStmt(parentKey []*datastore.Key, e *entity) (*stmt, error) {
@@ -775,14 +1316,15 @@ func (b *builder) putStmt(parentKey []*datastore.Key, e *entity) (*stmt, error)
 			return nil, nil
 		}
 
-		props[pkColumn] = Property{[]string{pkColumn}, typeOfPtrKey, stringPk(pk)}
+		props[pkColumn] = Property{name: []string{pkColumn}, typeOf: typeOfPtrKey, Value: stringPk(pk)}
+		props[parentColumn] = Property{name: []string{parentColumn}, typeOf: typeOfPtrKey, Value: pk.Parent}
 		f.Set(vi.Elem())
 		if i != 0 {
 			buf.WriteString(",")
 		}
 		vals := make([]interface{}, len(cols), len(cols))
 		for j, c := range cols {
-			vv, err := props[c].Interface()
+			vv, err := b.propertyValue(props[c])
 			if err != nil {
 				return nil, err
 			}
@@ -814,11 +1356,22 @@ func (b *builder) put(model interface{}, parentKey []*datastore.Key) error {
 	if e.slice.Elem().Len() <= 0 {
 		return nil
 	}
+	if e.isDynamic {
+		cmd, _, err := b.putDynamicStmt(parentKey, e)
+		if err != nil {
+			return err
+		}
+		return b.execWrite(OpCreate, cmd, e.Name())
+	}
 	cmd, err := b.putStmt(parentKey, e)
 	if err != nil {
 		return err
 	}
-	return b.db.client.execStmt(cmd)
+	if err := b.execWrite(OpCreate, cmd, e.Name()); err != nil {
+		return err
+	}
+	b.cacheEntities(e, false)
+	return nil
 }
 
 func (b *builder) upsert(model interface{}, parentKey []*datastore.Key) error {
@@ -830,15 +1383,22 @@ func (b *builder) upsert(model interface{}, parentKey []*datastore.Key) error {
 	if e.slice.Elem().Len() <= 0 {
 		return nil
 	}
-	cmd, err := b.putStmt(parentKey, e)
+	var (
+		cmd  *stmt
+		cols = e.Columns()
+	)
+	if e.isDynamic {
+		cmd, cols, err = b.putDynamicStmt(parentKey, e)
+	} else {
+		cmd, err = b.putStmt(parentKey, e)
+	}
 	if err != nil {
 		return err
 	}
-	cols := e.Columns()
 	omits := newDictionary(b.query.omits)
 	columns := make([]string, 0, len(cols))
 	for _, c := range cols {
-		if omits.has(c) || c == pkColumn || c == keyFieldName {
+		if omits.has(c) || c == pkColumn || c == keyFieldName || c == parentColumn {
 			continue
 		}
 		columns = append(columns, c)
@@ -851,7 +1411,11 @@ func (b *builder) upsert(model interface{}, parentKey []*datastore.Key) error {
 	}
 	buf.WriteString(";")
 	cmd.statement = buf
-	return b.db.client.execStmt(cmd)
+	if err := b.execWrite(OpUpsert, cmd, e.Name()); err != nil {
+		return err
+	}
+	b.cacheEntities(e, false)
+	return nil
 }
 
 func (b *builder) saveMutation(model interface{}) (*stmt, error) {
@@ -864,6 +1428,9 @@ func (b *builder) saveMutation(model interface{}) (*stmt, error) {
 		return nil, err
 	}
 	e.setName(b.query.table)
+	if e.isDynamic {
+		return b.saveDynamicMutation(e)
+	}
 	buf := new(bytes.Buffer)
 	args := make([]interface{}, 0)
 	buf.WriteString(fmt.Sprintf("UPDATE %s SET ", b.db.dialect.GetTable(e.Name())))
@@ -893,7 +1460,7 @@ func (b *builder) saveMutation(model interface{}) (*stmt, error) {
 		if omits.has(k) {
 			continue
 		}
-		it, err := p.Interface()
+		it, err := b.propertyValue(p)
 		if err != nil {
 			return nil, err
 		}
@@ -924,9 +1491,15 @@ func (b *builder) save(model interface{}) error {
 	if err != nil {
 		return err
 	}
-	if err := b.db.client.execStmt(cmd); err != nil {
+	e, err := newEntity(vv.Interface())
+	if err != nil {
+		return err
+	}
+	e.setName(b.query.table)
+	if err := b.execWrite(OpSave, cmd, e.Name()); err != nil {
 		return err
 	}
+	b.cacheEntities(e, false)
 	v.Elem().Set(vi.Index(0).Elem())
 	return nil
 }
@@ -934,6 +1507,7 @@ func (b *builder) save(model interface{}) error {
 func (b *builder) updateWithMap(v reflect.Value) (*stmt, error) {
 	buf := new(bytes.Buffer)
 	args := make([]interface{}, 0)
+	paths := make(map[string]interface{})
 	for _, k := range v.MapKeys() {
 		vv := v.MapIndex(k)
 		if k.Kind() != reflect.String {
@@ -943,21 +1517,47 @@ func (b *builder) updateWithMap(v reflect.Value) (*stmt, error) {
 		if kk == keyFieldName {
 			return nil, fmt.Errorf("goloquent: update __key__ is not allow")
 		}
+		if isJSONPathColumn(kk) {
+			paths[kk] = vv.Interface()
+			continue
+		}
 		buf.WriteString(fmt.Sprintf(" %s = %s,", b.db.dialect.Quote(kk), variable))
 		v, err := normalizeValue(vv.Interface())
 		if err != nil {
 			return nil, err
 		}
-		it, err := interfaceToValue(v)
-		if err != nil {
-			return nil, err
-		}
-		vi, err := marshal(it)
+		// only the value of `Enumerator` type is validated, the `enum=` tag of model is unknown for map,
+		// the plain string is checked by the enum column (or constraint) of the migrated table
+		vi, err := b.propertyValue(Property{name: []string{kk}, typeOf: reflect.TypeOf(vv.Interface()), Value: v})
 		if err != nil {
 			return nil, err
 		}
 		args = append(args, vi)
 	}
+
+	// partial update of json column, eg. `Address>region>regionCode`
+	cols, group, err := groupJSONPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+	d, isOk := b.db.dialect.(JSONUpdateDialect)
+	if !isOk && len(cols) > 0 {
+		return nil, errUnsupported(b.db.dialect, "partial json update")
+	}
+	for _, col := range cols {
+		if v.MapIndex(reflect.ValueOf(col).Convert(v.Type().Key())).IsValid() {
+			return nil, fmt.Errorf("goloquent: conflict update on column %q and it's json path", col)
+		}
+		expr, vals := b.db.dialect.Quote(col), make([]interface{}, 0)
+		for _, p := range group[col] {
+			expr, vals, err = d.UpdateJSON(expr, vals, p.paths, p.update)
+			if err != nil {
+				return nil, err
+			}
+		}
+		buf.WriteString(fmt.Sprintf(" %s = %s,", b.db.dialect.Quote(col), expr))
+		args = append(args, vals...)
+	}
 	buf.Truncate(buf.Len() - 1)
 	return &stmt{
 		statement: buf,
@@ -983,7 +1583,7 @@ func (b *builder) updateWithStruct(model interface{}) (*stmt, error) {
 		if name == keyFieldName || (!cols.has(name) && p.isZero()) {
 			continue
 		}
-		it, err := p.Interface()
+		it, err := b.propertyValue(p)
 		if err != nil {
 			return nil, err
 		}
@@ -998,6 +1598,19 @@ func (b *builder) updateWithStruct(model interface{}) (*stmt, error) {
 }
 
 func (b *builder) updateMulti(v interface{}) error {
+	cmd, table, err := b.updateStmt(v)
+	if err != nil || cmd == nil {
+		return err
+	}
+	if err := b.execWrite(OpUpdate, cmd, table); err != nil {
+		return err
+	}
+	b.db.invalidateEntities(table)
+	return nil
+}
+
+// updateStmt will return the statement of query based update, the statement is nil if nothing to update
+func (b *builder) updateStmt(v interface{}) (*stmt, string, error) {
 	vi := reflect.Indirect(reflect.ValueOf(v))
 	buf, args := new(bytes.Buffer), make([]interface{}, 0)
 	table := b.query.table
@@ -1005,33 +1618,33 @@ func (b *builder) updateMulti(v interface{}) error {
 		table = vi.Type().Name()
 	}
 	if table == "" {
-		return fmt.Errorf("goloquent: missing table name")
+		return nil, "", fmt.Errorf("goloquent: missing table name")
 	}
 	buf.WriteString(fmt.Sprintf("UPDATE %s SET", b.db.dialect.GetTable(table)))
 	switch vi.Type().Kind() {
 	case reflect.Map:
 		if vi.IsNil() || vi.Len() == 0 {
-			return nil
+			return nil, table, nil
 		}
 		cmd, err := b.updateWithMap(vi)
 		if err != nil {
-			return err
+			return nil, "", err
 		}
 		buf.WriteString(cmd.string())
 		args = append(args, cmd.arguments...)
 	case reflect.Struct:
 		cmd, err := b.updateWithStruct(v)
 		if err != nil {
-			return err
+			return nil, "", err
 		}
 		buf.WriteString(" " + cmd.string())
 		args = append(args, cmd.arguments...)
 	default:
-		return fmt.Errorf("goloquent: unsupported data type %v on `Update`", vi.Type())
+		return nil, "", fmt.Errorf("goloquent: unsupported data type %v on `Update`", vi.Type())
 	}
 	cmd, err := b.buildStmt(b.query)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 	if b.query.limit > 0 && !b.db.dialect.UpdateWithLimit() {
 		buf.WriteString(fmt.Sprintf(" WHERE %s IN (",
@@ -1045,10 +1658,10 @@ func (b *builder) updateMulti(v interface{}) error {
 		buf.WriteString(cmd.string())
 	}
 	buf.WriteString(";")
-	return b.db.client.execStmt(&stmt{
+	return &stmt{
 		statement: buf,
 		arguments: append(args, cmd.arguments...),
-	})
+	}, table, nil
 }
 
 func (b *builder) concatKeys(e *entity) (*stmt, error) {
@@ -1060,7 +1673,22 @@ func (b *builder) concatKeys(e *entity) (*stmt, error) {
 		if i != 0 {
 			buf.WriteString(",")
 		}
-		kk, isOk := mustGetField(f, e.field(keyFieldName)).Interface().(*datastore.Key)
+		var (
+			kk   *datastore.Key
+			isOk bool
+		)
+		if e.isDynamic {
+			if f.Kind() != reflect.Ptr {
+				f = f.Addr()
+			}
+			k, err := dynamicKey(f)
+			if err != nil {
+				return nil, err
+			}
+			kk, isOk = k, k != nil
+		} else {
+			kk, isOk = mustGetField(f, e.field(keyFieldName)).Interface().(*datastore.Key)
+		}
 		if !isOk {
 			return nil, fmt.Errorf("goloquent: entity %q has no primary key property", f.Type().Name())
 		}
@@ -1082,7 +1710,7 @@ func (b *builder) softDeleteStmt(e *entity) (*stmt, error) {
 	buf.WriteString(fmt.Sprintf("UPDATE %s SET ", b.db.dialect.GetTable(e.Name())))
 	buf.WriteString(fmt.Sprintf("%s = %s WHERE %s IN ",
 		b.db.dialect.Quote(softDeleteColumn), variable, b.db.dialect.Quote(pkColumn)))
-	args = append(args, time.Now().UTC().Format("2006-01-02 15:04:05"))
+	args = append(args, formatTime(time.Now(), 0))
 	ss, err := b.concatKeys(e)
 	if err != nil {
 		return nil, err
@@ -1096,6 +1724,9 @@ func (b *builder) softDeleteStmt(e *entity) (*stmt, error) {
 }
 
 func (b *builder) deleteStmt(e *entity, isSoftDelete bool) (*stmt, error) {
+	if err := b.resolveDynamic(e); err != nil {
+		return nil, err
+	}
 	buf, args := new(bytes.Buffer), make([]interface{}, 0)
 	if isSoftDelete && e.hasSoftDelete() {
 		return b.softDeleteStmt(e)
@@ -1125,42 +1756,62 @@ func (b *builder) delete(model interface{}, isSoftDelete bool) error {
 	if err != nil {
 		return err
 	}
-	return b.db.client.execStmt(cmd)
+	if err := b.execWrite(OpDelete, cmd, e.Name()); err != nil {
+		return err
+	}
+	b.cacheEntities(e, true)
+	return nil
 }
 
 func (b *builder) deleteByQuery() error {
+	cmd, err := b.deleteByQueryStmt()
+	if err != nil {
+		return err
+	}
+	if err := b.execWrite(OpFlush, cmd, b.query.table); err != nil {
+		return err
+	}
+	b.db.invalidateEntities(b.query.table)
+	return nil
+}
+
+func (b *builder) deleteByQueryStmt() (*stmt, error) {
 	query := b.query
 	cmd, err := b.buildStmt(query)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	buf := new(bytes.Buffer)
 	buf.WriteString(fmt.Sprintf("DELETE FROM %s", b.db.dialect.GetTable(query.table)))
 	buf.WriteString(cmd.string())
 	buf.WriteString(";")
 	cmd.statement = buf
-	return b.db.client.execStmt(cmd)
+	return cmd, nil
 }
 
 func (b *builder) truncate(tables ...string) error {
 	for _, n := range tables {
 		buf := new(bytes.Buffer)
 		buf.WriteString(fmt.Sprintf("TRUNCATE TABLE %s;", b.db.dialect.GetTable(n)))
-		if err := b.db.client.execStmt(&stmt{
+		if err := b.execWrite(OpTruncate, &stmt{
 			statement: buf,
-		}); err != nil {
+		}, n); err != nil {
 			return err
 		}
+		b.db.invalidateEntities(n)
 	}
 	return nil
 }
 
 func (b *builder) scan(dest ...interface{}) error {
 	query := b.query
-	table := query.table
 	buf := new(bytes.Buffer)
 	buf.WriteString(b.buildSelect(query).string())
-	buf.WriteString(fmt.Sprintf(" FROM %s", b.db.dialect.GetTable(table)))
+	from, err := b.buildFrom(query)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(from.string())
 	ss, err := b.buildStmt(b.query)
 	if err != nil {
 		return err
@@ -1170,6 +1821,8 @@ func (b *builder) scan(dest ...interface{}) error {
 	if err := b.db.client.execQueryRow(&stmt{
 		statement: buf,
 		arguments: ss.arguments,
+		crud:      OpGet,
+		table:     query.table,
 	}).Scan(dest...); err != nil {
 		return fmt.Errorf("goloquent: %v", err)
 	}
@@ -1187,6 +1840,9 @@ func (b *builder) runInTransaction(cb TransactionHandler) error {
 	}
 	db := b.db.clone()
 	db.client.sqlCommon = tx
+	db.client.txID = newTxID()
+	db.client.txStmts = new(sync.Map)
+	db.txWrites = &tableSet{tables: make(map[string]bool)}
 	defer func() {
 		if r := recover(); r != nil {
 			defer tx.Rollback()
@@ -1196,7 +1852,13 @@ func (b *builder) runInTransaction(cb TransactionHandler) error {
 	if err := cb(db); err != nil {
 		return err
 	}
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	tables := db.txWrites.list()
+	b.db.invalidate(tables...)
+	b.db.invalidateEntities(tables...)
+	return nil
 }
 
 func sha1Sign(s *Stmt) string {
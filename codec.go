@@ -0,0 +1,322 @@
+package goloquent
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// EncodeFunc : encode the custom type to the column value, the value must be one of
+// nil, string, bool, int64, uint64, float64, []byte or time.Time
+type EncodeFunc func(it interface{}) (interface{}, error)
+
+// DecodeFunc : decode the column value to the custom type, b is nil when the column is NULL
+type DecodeFunc func(b []byte) (interface{}, error)
+
+// SchemaFunc : the column schema of the custom type for the dialect, eg. `mysql`, `postgres`,
+// only `DataType`, `DefaultValue`, `IsUnsigned`, `IsNullable` and `CharSet` are used
+type SchemaFunc func(dialect string) Schema
+
+// Codec : the codec of custom type, it defines the storage, filtering and migration of the type
+type Codec struct {
+	Type   reflect.Type
+	Encode EncodeFunc
+	Decode DecodeFunc
+	Schema SchemaFunc
+
+	// scalar is the data type of column when the codec is derived from the interfaces of the type
+	scalar   reflect.Type
+	nullable bool
+	isJSON   bool
+}
+
+// codecValue is the encoded value of custom type, it's passed to the driver as it is
+type codecValue struct {
+	value interface{}
+}
+
+// RegisterType : register the codec of custom type, it's consulted by `SaveStruct`, `LoadStruct`,
+// the query filters and the migration. The pointer of the type is supported as well.
+func RegisterType(t reflect.Type, encode EncodeFunc, decode DecodeFunc, schema SchemaFunc) {
+	defaultRegistry.RegisterType(t, encode, decode, schema)
+}
+
+// RegisterType : register the codec of custom type to the registry
+func (r *Registry) RegisterType(t reflect.Type, encode EncodeFunc, decode DecodeFunc, schema SchemaFunc) {
+	if t == nil || encode == nil || decode == nil {
+		panic(fmt.Errorf("goloquent: type, encode and decode function are required to register type"))
+	}
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		panic(fmt.Errorf("goloquent: unable to register type %v, register the element type instead", t))
+	}
+	r.codecMu.Lock()
+	defer r.codecMu.Unlock()
+	r.codecs[t] = &Codec{Type: t, Encode: encode, Decode: decode, Schema: schema}
+	delete(r.derived, t)
+}
+
+// Codec : return the codec of the type, the registered codec take precedence over
+// the codec which derived from `driver.Valuer` and `sql.Scanner`, `encoding.TextMarshaler` or `json.Marshaler`
+func (r *Registry) Codec(t reflect.Type) (*Codec, bool) {
+	r.codecMu.RLock()
+	c, isOk := r.codecs[t]
+	if !isOk {
+		c, isOk = r.derived[t]
+	}
+	r.codecMu.RUnlock()
+	if isOk {
+		return c, c != nil
+	}
+
+	c = deriveCodec(t)
+	r.codecMu.Lock()
+	defer r.codecMu.Unlock()
+	// the type may be registered or derived by the others while deriving
+	if x, isOk := r.codecs[t]; isOk {
+		return x, true
+	}
+	if x, isOk := r.derived[t]; isOk {
+		return x, x != nil
+	}
+	r.derived[t] = c
+	return c, c != nil
+}
+
+func codecOf(t reflect.Type) *Codec {
+	if t == nil {
+		return nil
+	}
+	c, _ := defaultRegistry.Codec(t)
+	return c
+}
+
+func (c *Codec) encode(v reflect.Value) (interface{}, error) {
+	it, err := c.Encode(v.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("goloquent: unable to encode %v, %v", c.Type, err)
+	}
+	return codecValue{it}, nil
+}
+
+// decode will unquote the value if it's the element of json array
+func (c *Codec) decode(b []byte, esc bool) (interface{}, error) {
+	if esc && b != nil && !c.isJSON {
+		if b2s(b) == "null" {
+			b = nil
+		} else {
+			var str string
+			if err := json.Unmarshal(b, &str); err == nil {
+				b = []byte(str)
+			}
+		}
+	}
+	it, err := c.Decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("goloquent: unable to decode %q to %v, %v", b2s(b), c.Type, err)
+	}
+	if it == nil {
+		return reflect.Zero(c.Type).Interface(), nil
+	}
+	if t := reflect.TypeOf(it); t != c.Type {
+		return nil, fmt.Errorf("goloquent: decode function of %v returns unmatched data type %v", c.Type, t)
+	}
+	return it, nil
+}
+
+func (c *Codec) schema(d Dialect, dialect string, col Column, sc Schema) []Schema {
+	switch {
+	case c.Schema != nil:
+		s := c.Schema(dialect)
+		sc.DataType = s.DataType
+		sc.DefaultValue = s.DefaultValue
+		sc.IsUnsigned = s.IsUnsigned
+		sc.IsNullable = sc.IsNullable || s.IsNullable
+		sc.CharSet = s.CharSet
+	case c.scalar != nil:
+		// the column is same as the field of the scalar type, so the tag options still apply
+		f := col.field
+		f.typeOf, f.parent = c.scalar, nil
+		scs := d.GetSchema(Column{names: col.names, field: f})
+		for i := range scs {
+			scs[i].IsNullable = scs[i].IsNullable || sc.IsNullable || c.nullable
+		}
+		return scs
+	default:
+		sc.DefaultValue = OmitDefault(nil)
+		sc.DataType = "text"
+	}
+	return []Schema{sc}
+}
+
+var (
+	typeOfValuer          = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	typeOfScanner         = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	typeOfTextMarshaler   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	typeOfTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	typeOfJSONMarshaler   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	typeOfJSONUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// isNativeType is the type which is supported natively, the interfaces of it are ignored
+func isNativeType(t reflect.Type) bool {
+	switch t {
+	case typeOfByte, typeOfJSONRawMessage, typeOfTime, typeOfDate, typeOfDecimal, typeOfGeoPoint, typeOfSoftDelete:
+		return true
+	}
+	return false
+}
+
+// deriveCodec will derive the codec from the interfaces of the type, it's nil if the type doesn't implement any of them.
+// `driver.Valuer` and `sql.Scanner` are applied to any type, whereas `encoding.TextMarshaler` and
+// `json.Marshaler` are only applied to struct, array, slice and map, so the scalar type is still stored as it is.
+func deriveCodec(t reflect.Type) *Codec {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface || isNativeType(t) {
+		return nil
+	}
+	pt := reflect.PtrTo(t)
+	switch {
+	case pt.Implements(typeOfValuer) && pt.Implements(typeOfScanner):
+		c := &Codec{Type: t, Encode: encodeValuer, Decode: decodeScanner(t)}
+		c.scalar, c.nullable = valuerScalar(t)
+		return c
+	case !isScalarKind(t.Kind()) && pt.Implements(typeOfTextMarshaler) && pt.Implements(typeOfTextUnmarshaler):
+		return &Codec{Type: t, Encode: encodeText, Decode: decodeText(t), scalar: reflect.TypeOf("")}
+	case !isScalarKind(t.Kind()) && pt.Implements(typeOfJSONMarshaler) && pt.Implements(typeOfJSONUnmarshaler):
+		return &Codec{Type: t, Encode: encodeJSON, Decode: decodeJSON(t), scalar: typeOfJSONRawMessage, isJSON: true}
+	}
+	return nil
+}
+
+func isScalarKind(k reflect.Kind) bool {
+	_, isOk := scalarTypes[k]
+	return isOk
+}
+
+var scalarTypes = map[reflect.Kind]reflect.Type{
+	reflect.String:  reflect.TypeOf(""),
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+}
+
+// addressable will copy the value to the pointer, so the method with pointer receiver is callable
+func addressable(it interface{}) interface{} {
+	v := reflect.ValueOf(it)
+	nv := reflect.New(v.Type())
+	nv.Elem().Set(v)
+	return nv.Interface()
+}
+
+// valuerScalar will determine the column data type with the value of zero, eg. `sql.NullString` is nullable string
+func valuerScalar(t reflect.Type) (reflect.Type, bool) {
+	if v, err := addressable(reflect.Zero(t).Interface()).(driver.Valuer).Value(); err == nil && v != nil {
+		switch vi := v.(type) {
+		case string, bool, int64, float64, []byte, time.Time:
+			return reflect.TypeOf(vi), false
+		}
+	}
+	if st, isOk := scalarTypes[t.Kind()]; isOk {
+		return st, false
+	}
+	// the layout of `sql.NullString`, `sql.NullInt64` and etc
+	if t.Kind() == reflect.Struct && t.NumField() == 2 && t.Field(1).Type.Kind() == reflect.Bool {
+		if ft := t.Field(0).Type; isNativeType(ft) || isScalarKind(ft.Kind()) {
+			if st, isOk := scalarTypes[ft.Kind()]; isOk {
+				ft = st
+			}
+			return ft, true
+		}
+	}
+	return reflect.TypeOf(""), true
+}
+
+func encodeValuer(it interface{}) (interface{}, error) {
+	v, err := addressable(it).(driver.Valuer).Value()
+	if err != nil {
+		return nil, err
+	}
+	if dt, isOk := v.(time.Time); isOk {
+		return dt.UTC().Format(timeLayout), nil
+	}
+	return v, nil
+}
+
+func decodeScanner(t reflect.Type) DecodeFunc {
+	return func(b []byte) (interface{}, error) {
+		v := reflect.New(t)
+		s := v.Interface().(sql.Scanner)
+		if b == nil {
+			if err := s.Scan(nil); err != nil {
+				return nil, err
+			}
+			return v.Elem().Interface(), nil
+		}
+		if err := s.Scan(b); err != nil {
+			// the date time column is retrieved as text
+			dt, e := parseTime(b2s(b))
+			if e != nil {
+				return nil, err
+			}
+			if err := s.Scan(dt); err != nil {
+				return nil, err
+			}
+		}
+		return v.Elem().Interface(), nil
+	}
+}
+
+func encodeText(it interface{}) (interface{}, error) {
+	b, err := addressable(it).(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func decodeText(t reflect.Type) DecodeFunc {
+	return func(b []byte) (interface{}, error) {
+		v := reflect.New(t)
+		if b == nil {
+			return v.Elem().Interface(), nil
+		}
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText(b); err != nil {
+			return nil, err
+		}
+		return v.Elem().Interface(), nil
+	}
+}
+
+func encodeJSON(it interface{}) (interface{}, error) {
+	b, err := addressable(it).(json.Marshaler).MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(b), nil
+}
+
+func decodeJSON(t reflect.Type) DecodeFunc {
+	return func(b []byte) (interface{}, error) {
+		v := reflect.New(t)
+		if b == nil {
+			return v.Elem().Interface(), nil
+		}
+		if err := v.Interface().(json.Unmarshaler).UnmarshalJSON(b); err != nil {
+			return nil, err
+		}
+		return v.Elem().Interface(), nil
+	}
+}
@@ -0,0 +1,276 @@
+package goloquent
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+type testMoney struct {
+	Currency string
+	Amount   int64
+}
+
+type testWallet struct {
+	Key     *datastore.Key `goloquent:"__key__"`
+	Balance testMoney
+	Limit   *testMoney
+	History []testMoney
+}
+
+func init() {
+	RegisterType(reflect.TypeOf(testMoney{}),
+		func(it interface{}) (interface{}, error) {
+			m := it.(testMoney)
+			return fmt.Sprintf("%s %d", m.Currency, m.Amount), nil
+		},
+		func(b []byte) (interface{}, error) {
+			if b == nil {
+				return nil, nil
+			}
+			var m testMoney
+			if _, err := fmt.Sscanf(string(b), "%s %d", &m.Currency, &m.Amount); err != nil {
+				return nil, err
+			}
+			return m, nil
+		},
+		func(dialect string) Schema {
+			return Schema{DataType: "varchar(30)", DefaultValue: "MYR 0", CharSet: latin1CharSet}
+		},
+	)
+}
+
+func TestRegisterType(t *testing.T) {
+	codec, err := getStructCodec(&testWallet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codec.fields) != 4 {
+		t.Fatal("registered type shouldn't be treated as nested struct")
+	}
+
+	w := testWallet{
+		Balance: testMoney{"MYR", 100},
+		History: []testMoney{{"MYR", 1}, {"USD", 2}},
+	}
+	props, err := SaveStruct(&w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]interface{})
+	for k, p := range props {
+		v, err := p.Interface()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values[k] = v
+	}
+	if values["Balance"] != "MYR 100" || values["Limit"] != nil || values["History"] != `["MYR 1","USD 2"]` {
+		t.Fatalf(errUnexpectedResult, "SaveStruct")
+	}
+
+	it := &Iterator{
+		columns: []string{keyFieldName, "Balance", "Limit", "History"},
+		results: []map[string][]byte{{
+			"Balance": []byte("MYR 100"),
+			"Limit":   []byte("USD 5"),
+			"History": []byte(`["MYR 1","USD 2"]`),
+		}},
+	}
+	var out testWallet
+	if _, err := it.scan(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Balance != w.Balance || out.Limit == nil || *out.Limit != (testMoney{"USD", 5}) ||
+		!reflect.DeepEqual(out.History, w.History) {
+		t.Fatalf(errUnexpectedResult, "Iterator.scan")
+	}
+
+	var loaded testWallet
+	if err := LoadStruct(&loaded, map[string]interface{}{
+		keyFieldName: (*datastore.Key)(nil),
+		"Balance":    testMoney{"SGD", 3},
+		"Limit":      &testMoney{"SGD", 9},
+		"History":    []interface{}{testMoney{"SGD", 4}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Balance != (testMoney{"SGD", 3}) || loaded.Limit == nil || loaded.Limit.Amount != 9 || len(loaded.History) != 1 {
+		t.Fatalf(errUnexpectedResult, "LoadStruct")
+	}
+
+	f := Filter{field: "Balance", operator: Equal, value: testMoney{"MYR", 100}}
+	if v, err := f.Interface(); err != nil || v != "MYR 100" {
+		t.Fatalf(errUnexpectedResult, "Filter.Interface")
+	}
+
+	for _, c := range getColumns(nil, codec) {
+		if c.Name() != "Balance" {
+			continue
+		}
+		for _, d := range []Dialect{new(sequel), new(postgres)} {
+			sc := d.GetSchema(c)[0]
+			if sc.DataType != "varchar(30)" || sc.DefaultValue != "MYR 0" || sc.IsNullable {
+				t.Fatalf(errUnexpectedResult, "GetSchema")
+			}
+		}
+	}
+
+	if _, err := valueToInterface(reflect.TypeOf(testMoney{}), []byte("invalid"), false); err == nil ||
+		!strings.Contains(err.Error(), "unable to decode") {
+		t.Fatal("invalid value should return error")
+	}
+}
+
+type testUUID [4]byte
+
+func (u testUUID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(u[:])), nil
+}
+
+func (u *testUUID) UnmarshalText(b []byte) error {
+	_, err := hex.Decode(u[:], b)
+	return err
+}
+
+type testPoint struct {
+	X, Y int
+}
+
+func (p testPoint) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("[%d,%d]", p.X, p.Y)), nil
+}
+
+func (p *testPoint) UnmarshalJSON(b []byte) error {
+	var xy [2]int
+	if err := json.Unmarshal(b, &xy); err != nil {
+		return err
+	}
+	p.X, p.Y = xy[0], xy[1]
+	return nil
+}
+
+type testProfile struct {
+	Key      *datastore.Key `goloquent:"__key__"`
+	Nickname sql.NullString
+	Age      sql.NullInt64
+	LoginAt  sql.NullTime
+	UUID     testUUID
+	Location testPoint
+	Visited  []testPoint
+}
+
+func TestInterfaceCodec(t *testing.T) {
+	codec, err := getStructCodec(&testProfile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codec.fields) != 7 {
+		t.Fatal("type which implements the interfaces shouldn't be treated as nested struct")
+	}
+
+	p := testProfile{
+		Nickname: sql.NullString{String: "Joe", Valid: true},
+		UUID:     testUUID{0xde, 0xad, 0xbe, 0xef},
+		Location: testPoint{1, 2},
+		Visited:  []testPoint{{3, 4}},
+	}
+	props, err := SaveStruct(&p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]interface{})
+	for k, p := range props {
+		v, err := p.Interface()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values[k] = v
+	}
+	if values["Nickname"] != "Joe" || values["Age"] != nil || values["UUID"] != "deadbeef" ||
+		values["Location"] != "[1,2]" || values["Visited"] != "[[3,4]]" {
+		t.Fatalf(errUnexpectedResult, "SaveStruct")
+	}
+
+	it := &Iterator{
+		columns: []string{keyFieldName, "Nickname", "Age", "LoginAt", "UUID", "Location", "Visited"},
+		results: []map[string][]byte{{
+			"Nickname": []byte("Joe"),
+			"Age":      []byte("18"),
+			"LoginAt":  []byte("2020-01-02 03:04:05"),
+			"UUID":     []byte("deadbeef"),
+			"Location": []byte("[1,2]"),
+			"Visited":  []byte("[[3,4]]"),
+		}},
+	}
+	var out testProfile
+	if _, err := it.scan(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Nickname != p.Nickname || out.Age.Int64 != 18 || !out.LoginAt.Valid || out.LoginAt.Time.Hour() != 3 ||
+		out.UUID != p.UUID || out.Location != p.Location || !reflect.DeepEqual(out.Visited, p.Visited) {
+		t.Fatalf(errUnexpectedResult, "Iterator.scan")
+	}
+
+	f := Filter{field: "UUID", operator: Equal, value: &p.UUID}
+	if v, err := f.Interface(); err != nil || v != "deadbeef" {
+		t.Fatalf(errUnexpectedResult, "Filter.Interface")
+	}
+
+	expected := map[string]struct {
+		dataType   string
+		isNullable bool
+	}{
+		"Nickname": {"varchar(191)", true},
+		"Age":      {"bigint", true},
+		"LoginAt":  {"datetime", true},
+		"UUID":     {"varchar(191)", false},
+		"Location": {"json", false},
+	}
+	for _, c := range getColumns(nil, codec) {
+		e, isOk := expected[c.Name()]
+		if !isOk {
+			continue
+		}
+		if sc := new(sequel).GetSchema(c)[0]; sc.DataType != e.dataType || sc.IsNullable != e.isNullable {
+			t.Fatalf("unexpected schema of %q, %s", c.Name(), sc.DataType)
+		}
+	}
+}
+
+func TestCodecConcurrent(t *testing.T) {
+	r := NewRegistry()
+	types := []reflect.Type{reflect.TypeOf(testUUID{}), reflect.TypeOf(testPoint{}), reflect.TypeOf("")}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%5 == 0 {
+				r.RegisterType(reflect.TypeOf(testMoney{}),
+					func(it interface{}) (interface{}, error) { return "", nil },
+					func(b []byte) (interface{}, error) { return nil, nil }, nil)
+			}
+			for _, t := range types {
+				r.Codec(t)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if _, isOk := r.Codec(reflect.TypeOf(testUUID{})); !isOk {
+		t.Fatalf(errUnexpectedResult, "Registry.Codec")
+	}
+	if _, isOk := r.Codec(reflect.TypeOf("")); isOk {
+		t.Fatalf(errUnexpectedResult, "Registry.Codec")
+	}
+	if c, isOk := r.Codec(reflect.TypeOf(testMoney{})); !isOk || c.Type != reflect.TypeOf(testMoney{}) {
+		t.Fatalf(errUnexpectedResult, "Registry.Codec")
+	}
+}
@@ -2,196 +2,225 @@ package db
 
 import (
 	"database/sql"
-	"fmt"
-	"strings"
 
 	"cloud.google.com/go/datastore"
 	"github.com/si3nloong/goloquent"
 )
 
-// Connection :
-func Connection(driver string) *goloquent.DB {
-	driver = strings.TrimSpace(driver)
-	paths := strings.SplitN(driver, ":", 2)
-	if len(paths) != 2 {
-		panic(fmt.Errorf("goloquent: invalid connection name %q", driver))
-	}
-	x, isOk := connPool.Load(driver)
-	if !isOk {
-		panic(fmt.Errorf("goloquent: connection not found"))
-	}
-	pool := x.(map[string]*goloquent.DB)
-	for k, v := range pool {
-		if k == paths[1] {
-			return v
-		}
-		// return v
-	}
-	return nil
+// Connection : return the connection which opened by `Open`, eg. `Connection("mysql:test")`
+//
+// Deprecated: use `Get` or `Use` instead.
+func Connection(name string) *goloquent.DB {
+	return Use(name)
 }
 
 // Query :
 func Query(stmt string, args ...interface{}) (*sql.Rows, error) {
-	return defaultDB.Query(stmt, args...)
+	return Default().Query(stmt, args...)
+}
+
+// Raw :
+func Raw(stmt string, args ...interface{}) *goloquent.RawQuery {
+	return Default().Raw(stmt, args...)
 }
 
 // Exec :
 func Exec(stmt string, args ...interface{}) (sql.Result, error) {
-	return defaultDB.Exec(stmt, args...)
+	return Default().Exec(stmt, args...)
+}
+
+// Stats :
+func Stats() sql.DBStats {
+	return Default().Stats()
 }
 
 // Table :
 func Table(name string) *goloquent.Table {
-	return defaultDB.Table(name)
+	return Default().Table(name)
 }
 
 // Migrate :
 func Migrate(model ...interface{}) error {
-	return defaultDB.Migrate(model...)
+	return Default().Migrate(model...)
 }
 
 // Omit :
 func Omit(fields ...string) goloquent.Replacer {
-	return defaultDB.Omit(fields...)
+	return Default().Omit(fields...)
 }
 
 // Create :
 func Create(model interface{}, parentKey ...*datastore.Key) error {
 	if parentKey == nil {
-		return defaultDB.Create(model)
+		return Default().Create(model)
 	}
-	return defaultDB.Create(model, parentKey...)
+	return Default().Create(model, parentKey...)
 }
 
 // Upsert :
 func Upsert(model interface{}, parentKey ...*datastore.Key) error {
 	if parentKey == nil {
-		return defaultDB.Upsert(model)
+		return Default().Upsert(model)
 	}
-	return defaultDB.Upsert(model, parentKey...)
+	return Default().Upsert(model, parentKey...)
 }
 
 // Delete :
 func Delete(model interface{}) error {
-	return defaultDB.Delete(model)
+	return Default().Delete(model)
 }
 
 // Destroy :
 func Destroy(model interface{}) error {
-	return defaultDB.Destroy(model)
+	return Default().Destroy(model)
 }
 
 // Save :
 func Save(model interface{}) error {
-	return defaultDB.Save(model)
+	return Default().Save(model)
 }
 
 // Find :
 func Find(key *datastore.Key, model interface{}) error {
-	return defaultDB.Find(key, model)
+	return Default().Find(key, model)
 }
 
-// First :
-func First(model interface{}) error {
-	return defaultDB.First(model)
+// GetMulti :
+func GetMulti(keys []*datastore.Key, model interface{}) error {
+	return Default().GetMulti(keys, model)
 }
 
-// Get :
-func Get(model interface{}) error {
-	return defaultDB.Get(model)
+// First :
+func First(model interface{}) error {
+	return Default().First(model)
 }
 
 // Paginate :
 func Paginate(p *goloquent.Pagination, model interface{}) error {
-	return defaultDB.Paginate(p, model)
+	return Default().Paginate(p, model)
 }
 
 // NewQuery :
 func NewQuery() *goloquent.Query {
-	return defaultDB.NewQuery()
+	return Default().NewQuery()
 }
 
 // Select :
 func Select(fields ...string) *goloquent.Query {
-	return defaultDB.Select(fields...)
+	return Default().Select(fields...)
 }
 
 // Ancestor :
 func Ancestor(ancestor *datastore.Key) *goloquent.Query {
-	return defaultDB.NewQuery().Ancestor(ancestor)
+	return Default().NewQuery().Ancestor(ancestor)
 }
 
 // AnyOfAncestor :
 func AnyOfAncestor(ancestors ...*datastore.Key) *goloquent.Query {
-	return defaultDB.NewQuery().AnyOfAncestor(ancestors...)
+	return Default().NewQuery().AnyOfAncestor(ancestors...)
+}
+
+// Descendants :
+func Descendants(key *datastore.Key, depth int) *goloquent.Query {
+	return Default().NewQuery().Descendants(key, depth)
 }
 
 // Unscoped :
 func Unscoped() *goloquent.Query {
-	return defaultDB.NewQuery().Unscoped()
+	return Default().NewQuery().Unscoped()
 }
 
 // DistinctOn :
 func DistinctOn(fields ...string) *goloquent.Query {
-	return defaultDB.NewQuery().DistinctOn(fields...)
+	return Default().NewQuery().DistinctOn(fields...)
 }
 
 // Where :
 func Where(field string, operator string, value interface{}) *goloquent.Query {
-	return defaultDB.Where(field, operator, value)
+	return Default().Where(field, operator, value)
+}
+
+// WhereNear :
+func WhereNear(field string, point datastore.GeoPoint, meters float64) *goloquent.Query {
+	return Default().NewQuery().WhereNear(field, point, meters)
+}
+
+// WhereWithinBox :
+func WhereWithinBox(field string, sw, ne datastore.GeoPoint) *goloquent.Query {
+	return Default().NewQuery().WhereWithinBox(field, sw, ne)
+}
+
+// WhereContains :
+func WhereContains(field string, value interface{}) *goloquent.Query {
+	return Default().NewQuery().WhereContains(field, value)
+}
+
+// WhereOverlaps :
+func WhereOverlaps(field string, value interface{}) *goloquent.Query {
+	return Default().NewQuery().WhereOverlaps(field, value)
+}
+
+// WhereContainedBy :
+func WhereContainedBy(field string, value interface{}) *goloquent.Query {
+	return Default().NewQuery().WhereContainedBy(field, value)
 }
 
 // WhereEqual :
 func WhereEqual(field string, value interface{}) *goloquent.Query {
-	return defaultDB.NewQuery().WhereEqual(field, value)
+	return Default().NewQuery().WhereEqual(field, value)
 }
 
 // WhereNotEqual :
 func WhereNotEqual(field string, value interface{}) *goloquent.Query {
-	return defaultDB.NewQuery().WhereNotEqual(field, value)
+	return Default().NewQuery().WhereNotEqual(field, value)
 }
 
 // WhereNull :
 func WhereNull(field string) *goloquent.Query {
-	return defaultDB.NewQuery().WhereNull(field)
+	return Default().NewQuery().WhereNull(field)
 }
 
 // WhereNotNull :
 func WhereNotNull(field string) *goloquent.Query {
-	return defaultDB.NewQuery().WhereNotNull(field)
+	return Default().NewQuery().WhereNotNull(field)
 }
 
 // WhereJSON :
 func WhereJSON(field string, operator string, value interface{}) *goloquent.Query {
-	return defaultDB.NewQuery().WhereJSON(field, operator, value)
+	return Default().NewQuery().WhereJSON(field, operator, value)
 }
 
 // MatchAgainst :
 func MatchAgainst(fields []string, value ...string) *goloquent.Query {
-	return defaultDB.NewQuery().MatchAgainst(fields, value...)
+	return Default().NewQuery().MatchAgainst(fields, value...)
+}
+
+// Match :
+func Match(fields []string, query string, mode goloquent.SearchMode, language ...string) *goloquent.Query {
+	return Default().NewQuery().Match(fields, query, mode, language...)
 }
 
 // OrderBy :
 func OrderBy(fields ...interface{}) *goloquent.Query {
-	return defaultDB.NewQuery().OrderBy(fields...)
+	return Default().NewQuery().OrderBy(fields...)
 }
 
 // Limit :
 func Limit(limit int) *goloquent.Query {
-	return defaultDB.NewQuery().Limit(limit)
+	return Default().NewQuery().Limit(limit)
 }
 
 // Offset :
 func Offset(offset int) *goloquent.Query {
-	return defaultDB.NewQuery().Offset(offset)
+	return Default().NewQuery().Offset(offset)
 }
 
 // RunInTransaction :
 func RunInTransaction(cb goloquent.TransactionHandler) error {
-	return defaultDB.RunInTransaction(cb)
+	return Default().RunInTransaction(cb)
 }
 
 // Truncate :
 func Truncate(model ...interface{}) error {
-	return defaultDB.Truncate(model...)
+	return Default().Truncate(model...)
 }
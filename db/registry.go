@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/si3nloong/goloquent"
+)
+
+// registry is the named connections, the first registered connection is the default connection
+type registry struct {
+	mu    sync.RWMutex
+	conns map[string]*goloquent.DB
+	name  string
+}
+
+var conns = &registry{conns: make(map[string]*goloquent.DB)}
+
+// Register : register the connection with name, the first registered connection will become the default connection
+func Register(name string, conn *goloquent.DB) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("goloquent: connection name is required")
+	}
+	if conn == nil {
+		return fmt.Errorf("goloquent: nil connection %q", name)
+	}
+	conns.mu.Lock()
+	defer conns.mu.Unlock()
+	if _, isOk := conns.conns[name]; isOk {
+		return fmt.Errorf("goloquent: connection %q is already registered", name)
+	}
+	conns.conns[name] = conn
+	if conns.name == "" {
+		conns.name = name
+		defaultDB = conn
+	}
+	return nil
+}
+
+// replace will register the connection with name and return the previous connection of the name,
+// the default connection is replaced as well if the previous connection is the default connection
+func replace(name string, conn *goloquent.DB) *goloquent.DB {
+	conns.mu.Lock()
+	defer conns.mu.Unlock()
+	prev := conns.conns[name]
+	conns.conns[name] = conn
+	if conns.name == "" || conns.name == name {
+		conns.name = name
+		defaultDB = conn
+	}
+	return prev
+}
+
+// Get : return the registered connection
+func Get(name string) (*goloquent.DB, bool) {
+	conns.mu.RLock()
+	defer conns.mu.RUnlock()
+	conn, isOk := conns.conns[strings.TrimSpace(name)]
+	return conn, isOk
+}
+
+// Use : return the registered connection, it panics if the connection is not registered, use `Get` to check the existence
+func Use(name string) *goloquent.DB {
+	conn, isOk := Get(name)
+	if !isOk {
+		panic(fmt.Errorf("goloquent: connection %q is not registered", name))
+	}
+	return conn
+}
+
+// SetDefault : set the registered connection as the default connection which used by the package functions, eg. `db.Table`
+func SetDefault(name string) error {
+	name = strings.TrimSpace(name)
+	conns.mu.Lock()
+	defer conns.mu.Unlock()
+	conn, isOk := conns.conns[name]
+	if !isOk {
+		return fmt.Errorf("goloquent: connection %q is not registered", name)
+	}
+	conns.name = name
+	defaultDB = conn
+	return nil
+}
+
+// Default : return the default connection, nil if there is no registered connection
+func Default() *goloquent.DB {
+	conns.mu.RLock()
+	defer conns.mu.RUnlock()
+	return defaultDB
+}
+
+// Names : return the names of registered connections
+func Names() []string {
+	conns.mu.RLock()
+	defer conns.mu.RUnlock()
+	return sortedNames(conns.conns)
+}
+
+// CloseAll : close and unregister all the connections
+func CloseAll() error {
+	conns.mu.Lock()
+	defer conns.mu.Unlock()
+	errs := make(goloquent.MultiError, 0)
+	for _, n := range sortedNames(conns.conns) {
+		if err := conns.conns[n].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("goloquent: unable to close connection %q, %v", n, err))
+		}
+	}
+	conns.conns = make(map[string]*goloquent.DB)
+	conns.name = ""
+	defaultDB = nil
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Ping : check the health of all the registered connections
+func Ping(ctx context.Context) error {
+	conns.mu.RLock()
+	defer conns.mu.RUnlock()
+	errs := make(goloquent.MultiError, 0)
+	for _, n := range sortedNames(conns.conns) {
+		if err := conns.conns[n].Ping(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("goloquent: connection %q is unhealthy, %v", n, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func sortedNames(m map[string]*goloquent.DB) []string {
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/si3nloong/goloquent"
+)
+
+type nopDriver struct{}
+
+func (nopDriver) Open(name string) (driver.Conn, error) {
+	if name == "down" {
+		return nil, errors.New("connection refused")
+	}
+	return nopConn{}, nil
+}
+
+type nopConn struct{}
+
+func (nopConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("unsupported") }
+func (nopConn) Close() error                              { return nil }
+func (nopConn) Begin() (driver.Tx, error)                 { return nil, errors.New("unsupported") }
+
+func init() {
+	sql.Register("goloquent-nop", nopDriver{})
+}
+
+func newConn(t *testing.T, dsn string) *goloquent.DB {
+	conn, err := sql.Open("goloquent-nop", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dialect, _ := goloquent.GetDialect("common")
+	return goloquent.NewDB("common", goloquent.CharSet{}, conn, dialect, nil)
+}
+
+func TestRegistry(t *testing.T) {
+	defer CloseAll()
+
+	mysql, pg := newConn(t, ""), newConn(t, "down")
+	if err := Register("mysql", mysql); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register("postgres", pg); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register("mysql", pg); err == nil {
+		t.Fatal("duplicate connection name should return error")
+	}
+	if Default() != mysql {
+		t.Fatal("first registered connection should be the default connection")
+	}
+
+	if conn, isOk := Get("postgres"); !isOk || conn != pg || Use("postgres") != pg {
+		t.Fatal("registered connection not found")
+	}
+	if _, isOk := Get("sqlite"); isOk {
+		t.Fatal("unregistered connection shouldn't be found")
+	}
+	if err := SetDefault("sqlite"); err == nil {
+		t.Fatal("unregistered connection shouldn't be the default connection")
+	}
+	if err := SetDefault("postgres"); err != nil || Default() != pg {
+		t.Fatal("default connection should be changed")
+	}
+
+	err := Ping(context.Background())
+	if err == nil {
+		t.Fatal("unhealthy connection should return error")
+	}
+	if errs, isOk := err.(goloquent.MultiError); !isOk || len(errs) != 1 {
+		t.Fatal("only unhealthy connection should return error")
+	}
+
+	if err := CloseAll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(Names()) != 0 || Default() != nil {
+		t.Fatal("all connections should be unregistered")
+	}
+}
+
+func TestReplace(t *testing.T) {
+	defer CloseAll()
+
+	prev, conn := newConn(t, ""), newConn(t, "")
+	if err := Register("mysql:test", prev); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register("postgres:test", newConn(t, "")); err != nil {
+		t.Fatal(err)
+	}
+	// `Open` with the same name will close and replace the previous connection
+	if replace("mysql:test", conn) != prev {
+		t.Fatal("previous connection should be returned")
+	}
+	prev.Close()
+	if Use("mysql:test") != conn || Default() != conn {
+		t.Fatal("registered and default connection should be replaced")
+	}
+	if err := prev.Ping(context.Background()); err == nil {
+		t.Fatal("previous connection should be closed")
+	}
+	if replace("sqlite:test", newConn(t, "")) != nil || Default() != conn {
+		t.Fatal("new name shouldn't replace the default connection")
+	}
+}
+
+func TestDefaultConcurrent(t *testing.T) {
+	defer CloseAll()
+
+	if err := Register("mysql", newConn(t, "")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register("postgres", newConn(t, "")); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetDefault("postgres")
+		}()
+		go func() {
+			defer wg.Done()
+			if Table("User") == nil {
+				t.Error("package function should use the default connection")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,248 @@
+package goloquent
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var typeOfDecimal = reflect.TypeOf(Decimal{})
+
+const (
+	defaultDecimalPrecision = 36
+	defaultDecimalScale     = 18
+)
+
+// decimalVariable is the placeholder of decimal filter, so the column is compared as decimal instead of double
+var decimalVariable = fmt.Sprintf("CAST(%s AS DECIMAL(65,30))", variable)
+
+// Decimal : the exact decimal number, it's stored as `DECIMAL(p,s)` in mysql and `NUMERIC(p,s)` in postgres,
+// the precision and scale can be specified with the tag, eg. `goloquent:",precision=20,scale=4"`
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimal : create the decimal of `unscaled * 10^-scale`, eg. NewDecimal(1250, 2) is 12.50
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{unscaled: new(big.Int).Mul(big.NewInt(unscaled), pow10(-scale))}
+	}
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// ParseDecimal : parse the decimal from string, eg. `-12.50`
+func ParseDecimal(str string) (Decimal, error) {
+	s := strings.TrimSpace(str)
+	if s == "" {
+		return Decimal{}, fmt.Errorf("goloquent: invalid decimal value %q", str)
+	}
+	digits, scale := s, int32(0)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits = s[:i] + s[i+1:]
+		scale = int32(len(s) - i - 1)
+	}
+	n, isOk := new(big.Int).SetString(digits, 10)
+	if !isOk {
+		return Decimal{}, fmt.Errorf("goloquent: invalid decimal value %q", str)
+	}
+	return Decimal{unscaled: n, scale: scale}, nil
+}
+
+// MustDecimal : same as `ParseDecimal`, it panics if the string is invalid
+func MustDecimal(str string) Decimal {
+	d, err := ParseDecimal(str)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// rescale will return the unscaled value with the bigger scale
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale <= d.scale {
+		return new(big.Int).Set(d.int())
+	}
+	return new(big.Int).Mul(d.int(), pow10(scale-d.scale))
+}
+
+// Scale : number of digits after the decimal point
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign : return -1, 0 or 1
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero :
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp : compare the decimal, return -1 if d < d2, 0 if d == d2, 1 if d > d2
+func (d Decimal) Cmp(d2 Decimal) int {
+	scale := d.scale
+	if d2.scale > scale {
+		scale = d2.scale
+	}
+	return d.rescale(scale).Cmp(d2.rescale(scale))
+}
+
+// Equal :
+func (d Decimal) Equal(d2 Decimal) bool {
+	return d.Cmp(d2) == 0
+}
+
+// Add :
+func (d Decimal) Add(d2 Decimal) Decimal {
+	scale := d.scale
+	if d2.scale > scale {
+		scale = d2.scale
+	}
+	return Decimal{unscaled: new(big.Int).Add(d.rescale(scale), d2.rescale(scale)), scale: scale}
+}
+
+// Sub :
+func (d Decimal) Sub(d2 Decimal) Decimal {
+	return d.Add(d2.Neg())
+}
+
+// Mul :
+func (d Decimal) Mul(d2 Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.int(), d2.int()), scale: d.scale + d2.scale}
+}
+
+// Neg :
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Round : round the decimal to the number of places, half away from zero
+func (d Decimal) Round(places int32) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if places >= d.scale {
+		return Decimal{unscaled: d.rescale(places), scale: places}
+	}
+	q, r := new(big.Int).QuoRem(d.int(), pow10(d.scale-places), new(big.Int))
+	// |r| * 2 >= 10^(scale-places)
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(pow10(d.scale-places)) >= 0 {
+		q.Add(q, big.NewInt(int64(d.int().Sign())))
+	}
+	return Decimal{unscaled: q, scale: places}
+}
+
+// Float64 : the nearest float64 value of the decimal
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String : the decimal in plain notation, the trailing zeros of scale are kept, eg. `12.50`
+func (d Decimal) String() string {
+	n := d.int()
+	if d.scale <= 0 {
+		return n.String()
+	}
+	digits := new(big.Int).Abs(n).String()
+	if pad := int(d.scale) - len(digits) + 1; pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	i := len(digits) - int(d.scale)
+	str := digits[:i] + "." + digits[i:]
+	if n.Sign() < 0 {
+		return "-" + str
+	}
+	return str
+}
+
+// Value : implement `driver.Valuer`
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan : implement `sql.Scanner`, so the aggregate result is able to scan into decimal, eg. `SUM(Amount)`
+func (d *Decimal) Scan(src interface{}) error {
+	var str string
+	switch vi := src.(type) {
+	case nil:
+		*d = Decimal{}
+		return nil
+	case []byte:
+		str = string(vi)
+	case string:
+		str = vi
+	case int64:
+		*d = NewDecimal(vi, 0)
+		return nil
+	case float64:
+		str = strconv.FormatFloat(vi, 'f', -1, 64)
+	default:
+		return fmt.Errorf("goloquent: unable to scan %T into decimal", src)
+	}
+	v, err := ParseDecimal(str)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalJSON : the decimal is encoded as json string, so there is no precision lost
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON : accept both json string and number
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	if b == nil || b2s(b) == "null" {
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("goloquent: invalid decimal value %s", b)
+	}
+	return d.UnmarshalText([]byte(n))
+}
+
+// MarshalText :
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText :
+func (d *Decimal) UnmarshalText(b []byte) error {
+	v, err := ParseDecimal(b2s(b))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// isDecimalValue will check whether the filter value is decimal or slice of decimal
+func isDecimalValue(it interface{}) bool {
+	t := reflect.TypeOf(it)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	return t == typeOfDecimal
+}
@@ -0,0 +1,139 @@
+package goloquent
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+func TestDecimal(t *testing.T) {
+	d, err := ParseDecimal("-12.50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.String() != "-12.50" || d.Scale() != 2 || d.Sign() != -1 {
+		t.Fatalf(errUnexpectedResult, "ParseDecimal")
+	}
+	for _, str := range []string{"", ".", "1.2.3", "abc", "1e3"} {
+		if _, err := ParseDecimal(str); err == nil {
+			t.Fatalf("invalid decimal %q should return error", str)
+		}
+	}
+	if NewDecimal(5, 3).String() != "0.005" || NewDecimal(-5, 3).String() != "-0.005" || NewDecimal(12, -2).String() != "1200" {
+		t.Fatalf(errUnexpectedResult, "NewDecimal")
+	}
+
+	a, b := MustDecimal("0.1"), MustDecimal("0.2")
+	if a.Add(b).String() != "0.3" || a.Sub(b).String() != "-0.1" || a.Mul(b).String() != "0.02" {
+		t.Fatalf(errUnexpectedResult, "Decimal arithmetic")
+	}
+	if MustDecimal("1.50").Cmp(MustDecimal("1.5")) != 0 || a.Cmp(b) != -1 || (Decimal{}).Cmp(MustDecimal("0")) != 0 {
+		t.Fatalf(errUnexpectedResult, "Decimal.Cmp")
+	}
+	if MustDecimal("2.345").Round(2).String() != "2.35" || MustDecimal("-2.345").Round(2).String() != "-2.35" ||
+		MustDecimal("2.344").Round(2).String() != "2.34" || MustDecimal("2").Round(2).String() != "2.00" {
+		t.Fatalf(errUnexpectedResult, "Decimal.Round")
+	}
+
+	var s Decimal
+	if err := s.Scan([]byte("123456789012345678901234567890.123456789")); err != nil ||
+		s.String() != "123456789012345678901234567890.123456789" {
+		t.Fatalf(errUnexpectedResult, "Decimal.Scan")
+	}
+	if err := s.Scan(int64(7)); err != nil || s.String() != "7" {
+		t.Fatalf(errUnexpectedResult, "Decimal.Scan")
+	}
+
+	var j struct {
+		Amount Decimal
+		Fee    Decimal
+	}
+	if err := json.Unmarshal([]byte(`{"Amount":"10.05","Fee":0.25}`), &j); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := json.Marshal(j); string(b) != `{"Amount":"10.05","Fee":"0.25"}` {
+		t.Fatalf(errUnexpectedResult, "Decimal.MarshalJSON")
+	}
+}
+
+type testLedger struct {
+	Key     *datastore.Key `goloquent:"__key__"`
+	Amount  Decimal        `goloquent:",precision=20,scale=4"`
+	Balance *Decimal
+	Rate    float64 `goloquent:",precision=10,scale=6"`
+	History []Decimal
+}
+
+func TestDecimalField(t *testing.T) {
+	l := testLedger{
+		Amount:  MustDecimal("9999999999999999.9999"),
+		History: []Decimal{MustDecimal("0.1"), MustDecimal("0.2")},
+	}
+	props, err := SaveStruct(&l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := props["Amount"].Interface(); v != "9999999999999999.9999" {
+		t.Fatalf(errUnexpectedResult, "SaveStruct")
+	}
+	if v, _ := props["History"].Interface(); v != `["0.1","0.2"]` {
+		t.Fatalf(errUnexpectedResult, "SaveStruct")
+	}
+
+	it := &Iterator{
+		columns: []string{keyFieldName, "Amount", "Balance", "Rate", "History"},
+		results: []map[string][]byte{{
+			"Amount":  []byte("9999999999999999.9999"),
+			"Balance": []byte("0.0001"),
+			"Rate":    []byte("1.5"),
+			"History": []byte(`["0.1","0.2"]`),
+		}},
+	}
+	var out testLedger
+	if _, err := it.scan(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Amount.String() != "9999999999999999.9999" || out.Balance == nil || out.Balance.String() != "0.0001" ||
+		len(out.History) != 2 || !out.History[1].Equal(MustDecimal("0.2")) {
+		t.Fatalf(errUnexpectedResult, "Iterator.scan")
+	}
+
+	codec, err := getStructCodec(&l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][2]string{
+		"Amount":  {"decimal(20,4)", "numeric(20,4)"},
+		"Balance": {"decimal(36,18)", "numeric(36,18)"},
+		"Rate":    {"decimal(10,6)", "numeric(10,6)"},
+	}
+	for _, c := range getColumns(nil, codec) {
+		e, isOk := expected[c.Name()]
+		if !isOk {
+			continue
+		}
+		if sc := new(sequel).GetSchema(c)[0]; sc.DataType != e[0] {
+			t.Fatalf("unexpected mysql schema of %q, %s", c.Name(), sc.DataType)
+		}
+		if sc := new(postgres).GetSchema(c)[0]; sc.DataType != e[1] {
+			t.Fatalf("unexpected postgres schema of %q, %s", c.Name(), sc.DataType)
+		}
+	}
+
+	db := newFakeDB(t)
+	s, err := db.Table("Ledger").
+		Where("Amount", ">=", MustDecimal("10.50")).
+		WhereIn("Amount", []Decimal{MustDecimal("1"), MustDecimal("2")}).
+		ToSQL(OpGet, &[]testLedger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(s.Raw(), "`Amount` >= CAST(? AS DECIMAL(65,30))") ||
+		!strings.Contains(s.Raw(), "`Amount` IN (CAST(? AS DECIMAL(65,30)),CAST(? AS DECIMAL(65,30)))") ||
+		!reflect.DeepEqual(s.Arguments(), []interface{}{"10.50", "1", "2"}) {
+		t.Fatalf(errUnexpectedResult, "ToSQL")
+	}
+}
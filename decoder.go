@@ -1,7 +1,6 @@
 package goloquent
 
 import (
-	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -62,6 +61,9 @@ func escape(b []byte) string {
 // []interface{}, *struct
 func valueToInterface(t reflect.Type, v []byte, esc bool) (interface{}, error) {
 	var it interface{}
+	if c := codecOf(t); c != nil {
+		return c.decode(v, esc)
+	}
 
 	switch t {
 	case typeOfPtrKey:
@@ -83,9 +85,9 @@ func valueToInterface(t reflect.Type, v []byte, esc bool) (interface{}, error) {
 		if v == nil {
 			return time.Time{}, nil
 		}
-		var dt, err = time.Parse("2006-01-02 15:04:05", escape(v))
+		var dt, err = parseTime(escape(v))
 		if err != nil {
-			return nil, fmt.Errorf("goloquent: unable to parse %q to date time", b2s(v))
+			return nil, err
 		}
 		it = dt
 	case typeOfDate:
@@ -111,11 +113,20 @@ func valueToInterface(t reflect.Type, v []byte, esc bool) (interface{}, error) {
 			return nil, fmt.Errorf("goloquent: invalid date value %q", v)
 		}
 
+	case typeOfDecimal:
+		if v == nil || b2s(v) == "null" {
+			return Decimal{}, nil
+		}
+		var d, err = ParseDecimal(escape(v))
+		if err != nil {
+			return nil, err
+		}
+		it = d
 	case typeOfSoftDelete:
 		if v == nil {
 			return SoftDelete(nil), nil
 		}
-		var dt, err = time.Parse("2006-01-02 15:04:05", escape(v))
+		var dt, err = parseTime(escape(v))
 		if err != nil {
 			return nil, fmt.Errorf("goloquent: unable to parse %q to soft delete date time", b2s(v))
 		}
@@ -134,11 +145,11 @@ func valueToInterface(t reflect.Type, v []byte, esc bool) (interface{}, error) {
 		if v == nil || b2s(v) == "null" {
 			return datastore.GeoPoint{}, nil
 		}
-		var g geoLocation
-		if err := json.Unmarshal(bytes.Trim(v, `"`), &g); err != nil {
-			return nil, fmt.Errorf("goloquent: corrupted geolocation value, %s", b2s(v))
+		g, err := parseGeoPoint(v)
+		if err != nil {
+			return nil, err
 		}
-		it = datastore.GeoPoint{Lat: g.Latitude, Lng: g.Longitude}
+		it = g
 	default:
 		switch t.Kind() {
 		case reflect.String:
@@ -195,6 +206,23 @@ func valueToInterface(t reflect.Type, v []byte, esc bool) (interface{}, error) {
 				var arr []interface{}
 				return arr, nil
 			}
+			// native array of postgres
+			if isArrayLiteral(v) {
+				elems, err := parseArray(v)
+				if err != nil {
+					return nil, err
+				}
+				arr := make([]interface{}, 0, len(elems))
+				for _, elem := range elems {
+					var vv, err = valueToInterface(t.Elem(), elem, true)
+					if err != nil {
+						return nil, err
+					}
+					arr = append(arr, vv)
+				}
+				return arr, nil
+			}
+
 			var b []*json.RawMessage
 			if err := json.Unmarshal(v, &b); err != nil {
 				return nil, fmt.Errorf("goloquent: corrupted slice value, %v", err)
@@ -299,6 +327,15 @@ func isZero(v reflect.Value) bool {
 }
 
 func loadField(v reflect.Value, it interface{}) error {
+	if c := codecOf(v.Type()); c != nil {
+		vi := reflect.ValueOf(it)
+		if !vi.IsValid() || vi.Type() != c.Type {
+			return unmatchDataType(v.Interface(), it)
+		}
+		v.Set(vi)
+		return nil
+	}
+
 	switch v.Kind() {
 	case reflect.String:
 		x, isOk := it.(string)
@@ -397,6 +434,12 @@ func loadField(v reflect.Value, it interface{}) error {
 				return unmatchDataType(x, it)
 			}
 			v.Set(reflect.ValueOf(x))
+		case typeOfDecimal:
+			x, isOk := it.(Decimal)
+			if !isOk {
+				return unmatchDataType(x, it)
+			}
+			v.Set(reflect.ValueOf(x))
 		case typeOfSoftDelete:
 			x, isOk := it.(SoftDelete)
 			if !isOk {
@@ -3,6 +3,8 @@ package goloquent
 import (
 	"fmt"
 	"testing"
+
+	"cloud.google.com/go/datastore"
 )
 
 func TestEscape(t *testing.T) {
@@ -54,3 +56,30 @@ func TestLoadStructField(t *testing.T) {}
 func TestLoadField(t *testing.T) {
 
 }
+
+func TestIteratorKey(t *testing.T) {
+	it := &Iterator{table: "User", position: -1}
+	it.put(0, pkColumn, []byte(`Parent,'a'/1001`))
+	it.patchKey()
+	it.put(1, pkColumn, []byte(`1002`))
+	it.patchKey()
+
+	keys := make([]*datastore.Key, 0)
+	for it.Next() {
+		k, err := it.Key()
+		if err != nil {
+			t.Fatalf("Unexpected err, %v", err)
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 keys, but get %d", len(keys))
+	}
+	parent := datastore.NameKey("Parent", "a", nil)
+	if !keys[0].Equal(datastore.IDKey("User", 1001, parent)) {
+		t.Fatalf(errUnexpectedResult, "Iterator.Key")
+	}
+	if !keys[1].Equal(datastore.IDKey("User", 1002, nil)) {
+		t.Fatalf(errUnexpectedResult, "Iterator.Key")
+	}
+}
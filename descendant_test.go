@@ -0,0 +1,84 @@
+package goloquent
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+func TestBuildDescendant(t *testing.T) {
+	db := newFakeDB(t)
+	b := newBuilder(db.NewQuery())
+	db.parents.Store(b.query.table, true)
+	parent := datastore.NameKey("Group", "a_b%", nil)
+	k := datastore.IDKey("Team", 10, parent)
+	anc := stringifyKey(k)
+
+	str, args, _ := b.buildDescendant(b.query, k, 1)
+	if str != "`$Parent` = ??" || !reflect.DeepEqual(args, []interface{}{anc}) {
+		t.Fatalf(errUnexpectedResult, "buildDescendant")
+	}
+
+	// the wildcard characters of key name must not be matched as pattern
+	pattern := `Group,'a\_b\%25'/Team,10/%`
+	str, args, _ = b.buildDescendant(b.query, k, 0)
+	if str != "(`$Parent` = ?? OR (`$Parent` LIKE ??))" ||
+		!reflect.DeepEqual(args, []interface{}{anc, pattern}) {
+		t.Fatalf(errUnexpectedResult, "buildDescendant")
+	}
+
+	// the depth is limited by the number of delimeters of parent key path
+	str, args, _ = b.buildDescendant(b.query, k, 3)
+	if str != "(`$Parent` = ?? OR (`$Parent` LIKE ?? AND LENGTH(`$Parent`) - LENGTH(REPLACE(`$Parent`, '/', '')) <= ??))" ||
+		!reflect.DeepEqual(args, []interface{}{anc, pattern, 3}) {
+		t.Fatalf(errUnexpectedResult, "buildDescendant")
+	}
+
+	// the table without `$Parent` column is not migrated yet, the ancestor is matched by `$Key`
+	db.resetColumns(b.query.table)
+	str, args, err := b.buildDescendant(b.query, k, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if str != "`$Key` LIKE ??" || !reflect.DeepEqual(args, []interface{}{"%" + anc + "/%"}) {
+		t.Fatalf(errUnexpectedResult, "buildDescendant")
+	}
+	if _, _, err := b.buildDescendant(b.query, k, 1); err == nil {
+		t.Fatal("descendants with depth should return error when the table has no parent column")
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	type Member struct {
+		Key  *datastore.Key `goloquent:"__key__"`
+		Name string
+	}
+
+	db := newFakeDB(t, new(mysql))
+	k := datastore.NameKey("Team", "t1", nil)
+	db.parents.Store("Member", true)
+	fakeMu.Lock()
+	fakeStatements = nil
+	fakeMu.Unlock()
+	members := make([]Member, 0)
+	if err := db.Descendants(k, 2).Get(&members); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AnyOfAncestor(k, datastore.NameKey("Team", "t2", nil)).Get(&members); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	if len(fakeStatements) != 2 {
+		t.Fatalf("unexpected statements, %v", fakeStatements)
+	}
+	if !strings.Contains(fakeStatements[0], "(`$Parent` = ? OR (`$Parent` LIKE ? AND LENGTH(`$Parent`) - LENGTH(REPLACE(`$Parent`, '/', '')) <= ?))") {
+		t.Fatalf("unexpected descendant statement, %s", fakeStatements[0])
+	}
+	if !strings.Contains(fakeStatements[1], "((`$Parent` = ? OR (`$Parent` LIKE ?)) OR (`$Parent` = ? OR (`$Parent` LIKE ?)))") {
+		t.Fatalf("unexpected ancestor statement, %s", fakeStatements[1])
+	}
+}
@@ -3,10 +3,15 @@ package goloquent
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"time"
+
+	"cloud.google.com/go/datastore"
 )
 
-// Dialect :
+// Dialect : the optional capabilities are detected with the interfaces below, eg. `GeoDialect`,
+// the unsupported capability will return error
 type Dialect interface {
 	Open(c Config) (*sql.DB, error)
 	SetDB(db Client)
@@ -31,6 +36,54 @@ type Dialect interface {
 	ReplaceInto(src, dst string) error
 }
 
+// GeoDialect : the optional capability of dialect for `datastore.GeoPoint`, eg. `WhereNear` and `expr.Distance`,
+// the geo point is stored as json if it's not implemented
+type GeoDialect interface {
+	FilterGeo(f Filter) (s string, args []interface{}, err error)
+	GeoDistance(field string, g datastore.GeoPoint) (s string, args []interface{})
+	GeoValue(g datastore.GeoPoint) interface{}
+}
+
+// JSONIndexDialect : the optional capability of dialect for indexing the json path, eg. `goloquent:",index=region.regionCode"`
+type JSONIndexDialect interface {
+	AddJSONIndex(tb, field, path string) error
+}
+
+// JSONUpdateDialect : the optional capability of dialect for partial update of json column, eg. `goloquent.JSONSet`
+type JSONUpdateDialect interface {
+	UpdateJSON(expr string, args []interface{}, paths []string, u JSONUpdate) (s string, vals []interface{}, err error)
+}
+
+// FullTextDialect : the optional capability of dialect for full text search, eg. `Match` and `expr.Relevance`
+type FullTextDialect interface {
+	FilterFullText(f Filter) (s string, args []interface{}, err error)
+	FullTextRelevance(fields []string, query, language string, mode SearchMode, perField bool) (s string, args []interface{}, err error)
+	AddFullTextIndex(tb, field, language string) error
+}
+
+// TimeDialect : the optional capability of dialect for formatting `time.Time` with the fractional seconds precision,
+// the time is formatted in UTC with the full precision if it's not implemented
+type TimeDialect interface {
+	TimeValue(t time.Time, precision int) interface{}
+}
+
+// ArrayDialect : the optional capability of dialect for native array, eg. `WhereContains` and `goloquent:",array"`,
+// the slice is stored as json if it's not implemented
+type ArrayDialect interface {
+	FilterArray(f Filter) (s string, args []interface{}, err error)
+	ArrayValue(v []interface{}) (interface{}, error)
+}
+
+// ExplainDialect : the optional capability of dialect for `Query.Explain`
+type ExplainDialect interface {
+	Explain(s *Stmt) (*Plan, error)
+}
+
+// errUnsupported will return the error of the optional capability which is not implemented by the dialect
+func errUnsupported(d Dialect, feature string) error {
+	return fmt.Errorf("goloquent: dialect %T doesn't support %s", d, feature)
+}
+
 var (
 	dialects = make(map[string]Dialect)
 )
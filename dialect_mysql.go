@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/si3nloong/goloquent/types"
@@ -19,7 +20,16 @@ type mysql struct {
 
 const minVersion = "5.7"
 
-var _ Dialect = new(mysql)
+var (
+	_ Dialect           = new(mysql)
+	_ GeoDialect        = new(mysql)
+	_ JSONIndexDialect  = new(mysql)
+	_ JSONUpdateDialect = new(mysql)
+	_ FullTextDialect   = new(mysql)
+	_ TimeDialect       = new(mysql)
+	_ ArrayDialect      = new(mysql)
+	_ ExplainDialect    = new(mysql)
+)
 
 func init() {
 	RegisterDialect("mysql", new(mysql))
@@ -27,6 +37,13 @@ func init() {
 
 // Open :
 func (s *mysql) Open(conf Config) (*sql.DB, error) {
+	if conf.DSN != "" {
+		dsn, err := mysqlDSN(conf.DSN, conf.CharSet)
+		if err != nil {
+			return nil, err
+		}
+		return s.open(dsn, conf)
+	}
 	addr, buf := "@", new(bytes.Buffer)
 	buf.WriteString(conf.Username + ":" + conf.Password)
 	if conf.UnixSocket != "" {
@@ -48,12 +65,31 @@ func (s *mysql) Open(conf Config) (*sql.DB, error) {
 		buf.WriteString("&tls=" + conf.TLSConfig)
 	}
 	log.Println("Connection String :", buf.String())
-	client, err := sql.Open("mysql", buf.String())
+	return s.open(buf.String(), conf)
+}
+
+func (s *mysql) open(dsn string, conf Config) (*sql.DB, error) {
+	if conf.Pool.MaxOpenConns == 0 {
+		conf.Pool.MaxOpenConns = 300
+	}
+	return openDB("mysql", dsn, conf)
+}
+
+// boolColumns : bool is stored as `tinyint(1)`, the type name of driver is `TINYINT` which is same as int8
+func (s mysql) boolColumns(table string) []string {
+	columns := make([]string, 0)
+	stmt := "SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND COLUMN_TYPE = 'tinyint(1)';"
+	rows, err := s.db.Query(stmt, s.CurrentDB(), table)
 	if err != nil {
-		return nil, err
+		return columns
 	}
-	client.SetMaxOpenConns(300)
-	return client, nil
+	defer rows.Close()
+	for rows.Next() {
+		var col string
+		rows.Scan(&col)
+		columns = append(columns, col)
+	}
+	return columns
 }
 
 // Version :
@@ -117,6 +153,9 @@ func (s mysql) CreateTable(table string, columns []Column) error {
 			buf.WriteString(fmt.Sprintf("%s %s,", s.Quote(ss.Name), s.DataType(ss)))
 			if ss.IsIndexed || c.field.typeOf == typeOfSoftDelete {
 				idx := fmt.Sprintf("%s_%s_%s", table, ss.Name, "idx")
+				if ss.isSpatial() {
+					buf.WriteString("SPATIAL ")
+				}
 				buf.WriteString(fmt.Sprintf("INDEX %s (%s),", s.Quote(idx), s.Quote(ss.Name)))
 			}
 		}
@@ -124,13 +163,50 @@ func (s mysql) CreateTable(table string, columns []Column) error {
 	buf.WriteString(fmt.Sprintf("PRIMARY KEY (%s)", s.Quote(pkColumn)))
 	buf.WriteString(fmt.Sprintf(") ENGINE=InnoDB DEFAULT CHARSET=%s COLLATE=%s;",
 		s.Quote(s.db.CharSet.Encoding), s.Quote(s.db.CharSet.Collation)))
-	return s.db.execStmt(&stmt{statement: buf})
+	return s.db.execStmt(&stmt{statement: buf, crud: OpMigrate, table: table})
+}
+
+// convertGeoColumn will convert the json geo point column to point column,
+// the point is backfilled into the new column and swapped with the json column
+func (s *mysql) convertGeoColumn(table string, sc Schema) error {
+	tb, col, tmp := s.GetTable(table), s.Quote(sc.Name), s.Quote(sc.Name+"_point")
+	point := fmt.Sprintf("ST_GeomFromText(CONCAT('POINT(', COALESCE(%s->>'$.longitude', 0), ' ', COALESCE(%s->>'$.latitude', 0), ')'), %d, 'axis-order=long-lat')",
+		col, col, geoSRID)
+	update := fmt.Sprintf("UPDATE %s SET %s = %s;", tb, tmp, point)
+	if sc.IsNullable {
+		update = fmt.Sprintf("UPDATE %s SET %s = %s WHERE JSON_TYPE(%s) = 'OBJECT';", tb, tmp, point, col)
+	}
+	for _, str := range []string{
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s POINT SRID %d NULL AFTER %s;", tb, tmp, geoSRID, col),
+		update,
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s, RENAME COLUMN %s TO %s;", tb, col, tmp, col),
+	} {
+		if err := s.db.execStmt(&stmt{statement: bytes.NewBufferString(str), crud: OpMigrate, table: table}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *mysql) AlterTable(table string, columns []Column, unsafe bool) error {
 	cols := types.StringSlice(s.GetColumns(table))
 	idxs := types.StringSlice(s.GetIndexes(table))
 
+	// the geo point was stored as json, it cannot be modified to point directly
+	dataTypes := make(map[string]string)
+	if s.hasSpatial() {
+		dataTypes = s.columnTypes(table)
+	}
+	for _, c := range columns {
+		for _, ss := range s.GetSchema(c) {
+			if dt, isOk := dataTypes[ss.Name]; isOk && ss.isSpatial() && dt != "point" {
+				if err := s.convertGeoColumn(table, ss); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	var idx string
 	blr := new(bytes.Buffer)
 	blr.WriteString(`ALTER TABLE ` + s.GetTable(table) + ` `)
@@ -151,7 +227,11 @@ func (s *mysql) AlterTable(table string, columns []Column, unsafe bool) error {
 				idx = table + `_` + ss.Name + `_idx`
 				if idxs.IndexOf(idx) < 0 {
 					blr.WriteRune(',')
-					blr.WriteString(`ADD INDEX ` + s.Quote(idx))
+					if ss.isSpatial() {
+						blr.WriteString(`ADD SPATIAL INDEX ` + s.Quote(idx))
+					} else {
+						blr.WriteString(`ADD INDEX ` + s.Quote(idx))
+					}
 					blr.WriteString(` (` + s.Quote(ss.Name) + `)`)
 				}
 			}
@@ -169,7 +249,21 @@ func (s *mysql) AlterTable(table string, columns []Column, unsafe bool) error {
 	blr.WriteString(` CHARACTER SET ` + s.Quote(s.db.CharSet.Encoding))
 	blr.WriteString(` COLLATE ` + s.Quote(s.db.CharSet.Collation))
 	blr.WriteRune(';')
-	return s.db.execStmt(&stmt{statement: blr})
+	if err := s.db.execStmt(&stmt{statement: blr, crud: OpMigrate, table: table}); err != nil {
+		return err
+	}
+	if cols.IndexOf(parentColumn) > -1 {
+		return nil
+	}
+
+	// backfill the parent key path of the existing records
+	pk, parent := s.Quote(pkColumn), s.Quote(parentColumn)
+	buf := new(bytes.Buffer)
+	buf.WriteString(fmt.Sprintf("UPDATE %s SET %s = ", s.GetTable(table), parent))
+	buf.WriteString(fmt.Sprintf("LEFT(%s, LENGTH(%s) - LENGTH(SUBSTRING_INDEX(%s, '%s', -1)) - 1)",
+		pk, pk, pk, keyDelimeter))
+	buf.WriteString(fmt.Sprintf(" WHERE %s LIKE '%%%s%%';", pk, keyDelimeter))
+	return s.db.execStmt(&stmt{statement: buf, crud: OpMigrate, table: table})
 }
 
 func (s mysql) ToString(it interface{}) string {
@@ -207,11 +301,19 @@ func (s mysql) UpdateWithLimit() bool {
 }
 
 func (s mysql) ReplaceInto(src, dst string) error {
+	// generated column of json index is not writable, so the columns must be listed explicitly
+	cols := make([]string, 0)
+	for _, c := range s.GetColumns(src) {
+		if isJSONPathColumn(c) {
+			continue
+		}
+		cols = append(cols, s.Quote(c))
+	}
 	src, dst = s.GetTable(src), s.GetTable(dst)
 	buf := new(bytes.Buffer)
 	buf.WriteString("REPLACE INTO ")
-	buf.WriteString(dst + " ")
-	buf.WriteString("SELECT * FROM ")
+	buf.WriteString(dst + " (" + strings.Join(cols, ",") + ") ")
+	buf.WriteString("SELECT " + strings.Join(cols, ",") + " FROM ")
 	buf.WriteString(src)
 	buf.WriteString(";")
 	return s.db.execStmt(&stmt{
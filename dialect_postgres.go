@@ -7,15 +7,27 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
+
+	"cloud.google.com/go/datastore"
 )
 
 type postgres struct {
 	sequel
 }
 
-var _ Dialect = new(postgres)
+var (
+	_ Dialect           = new(postgres)
+	_ GeoDialect        = new(postgres)
+	_ JSONIndexDialect  = new(postgres)
+	_ JSONUpdateDialect = new(postgres)
+	_ FullTextDialect   = new(postgres)
+	_ TimeDialect       = new(postgres)
+	_ ArrayDialect      = new(postgres)
+	_ ExplainDialect    = new(postgres)
+)
 
 func init() {
 	RegisterDialect("postgres", new(postgres))
@@ -27,6 +39,10 @@ func (p postgres) escapeSingleQuote(n string) string {
 
 // Open :
 func (p *postgres) Open(conf Config) (*sql.DB, error) {
+	// the driver accept both url and key-value form
+	if conf.DSN != "" {
+		return openDB("postgres", conf.DSN, conf)
+	}
 	buf := new(bytes.Buffer)
 	buf.WriteString(fmt.Sprintf("user='%s' ", p.escapeSingleQuote(conf.Username)))
 	buf.WriteString(fmt.Sprintf("password='%s' ", p.escapeSingleQuote(conf.Password)))
@@ -45,11 +61,7 @@ func (p *postgres) Open(conf Config) (*sql.DB, error) {
 	buf.WriteString(fmt.Sprintf("dbname='%s' ", p.escapeSingleQuote(conf.Database)))
 	buf.WriteString("sslmode=disable")
 	log.Println("Connection String :", buf.String())
-	client, err := sql.Open("postgres", buf.String())
-	if err != nil {
-		return nil, err
-	}
-	return client, nil
+	return openDB("postgres", buf.String(), conf)
 }
 
 // GetTable :
@@ -78,7 +90,7 @@ func (p postgres) Bind(i uint) string {
 }
 
 func (p postgres) SplitJSON(name string) string {
-	paths := strings.SplitN(name, ">", 2)
+	paths := strings.SplitN(name, jsonDelimeter, 2)
 	if len(paths) <= 1 {
 		return p.Quote(paths[0])
 	}
@@ -97,6 +109,9 @@ func (p postgres) JSONMarshal(v interface{}) (b json.RawMessage) {
 	case string:
 		b = json.RawMessage(fmt.Sprintf("%q", vi))
 	default:
+		if bb, err := json.Marshal(vi); err == nil {
+			return json.RawMessage(bb)
+		}
 		b = json.RawMessage(fmt.Sprintf("%v", vi))
 	}
 	return
@@ -121,6 +136,10 @@ func (p postgres) FilterJSON(f Filter) (string, []interface{}, error) {
 		buf.WriteString(fmt.Sprintf("(%s) > %s", name, variable))
 	case GreaterEqual:
 		buf.WriteString(fmt.Sprintf("(%s) >= %s", name, variable))
+	case LessThan:
+		buf.WriteString(fmt.Sprintf("(%s) < %s", name, variable))
+	case LessEqual:
+		buf.WriteString(fmt.Sprintf("(%s) <= %s", name, variable))
 	case In:
 		x, isOk := vv.([]interface{})
 		if !isOk {
@@ -187,6 +206,183 @@ func (p postgres) FilterJSON(f Filter) (string, []interface{}, error) {
 	return buf.String(), args, nil
 }
 
+func (p postgres) jsonPath(paths []string) (string, []interface{}) {
+	args := make([]interface{}, len(paths))
+	for i, path := range paths {
+		args[i] = path
+	}
+	return "ARRAY[" + strings.TrimSuffix(strings.Repeat(variable+",", len(paths)), ",") + "]::text[]", args
+}
+
+// UpdateJSON : wrap the expression with the partial update function of json path
+func (p postgres) UpdateJSON(expr string, args []interface{}, paths []string, u JSONUpdate) (string, []interface{}, error) {
+	path, pathArgs := p.jsonPath(paths)
+	vals := append(append([]interface{}{}, args...), pathArgs...)
+	if u.op == jsonRemove {
+		return fmt.Sprintf("(%s #- %s)", expr, path), vals, nil
+	}
+	if u.op == jsonArrayAppend {
+		u.value = []interface{}{u.value}
+	}
+	b, err := u.Value()
+	if err != nil {
+		return "", nil, err
+	}
+	if u.op == jsonArrayAppend {
+		vals = append(append(vals, args...), pathArgs...)
+		return fmt.Sprintf("jsonb_set(%s, %s, COALESCE(%s #> %s, '[]'::jsonb) || %s::jsonb, true)",
+			expr, path, expr, path, variable), append(vals, string(b)), nil
+	}
+	return fmt.Sprintf("jsonb_set(%s, %s, %s::jsonb, true)", expr, path, variable),
+		append(vals, string(b)), nil
+}
+
+func (p postgres) tsVector(field, cfg string) string {
+	return fmt.Sprintf("to_tsvector('%s'::regconfig, %s)", cfg, p.Quote(field))
+}
+
+// tsQueryFunc : the boolean mode accepts the operators of web search, eg. `"phrase"` and `-term`
+func (p postgres) tsQueryFunc(mode SearchMode) string {
+	if mode == BooleanMode {
+		return "websearch_to_tsquery"
+	}
+	return "plainto_tsquery"
+}
+
+// FilterFullText : the expression of tsvector is same as the index, so the gin index is applicable
+func (p postgres) FilterFullText(f Filter) (string, []interface{}, error) {
+	vi, isOk := f.value.(fullTextSearch)
+	if !isOk {
+		return "", nil, fmt.Errorf("goloquent: invalid full text search value %v", f.value)
+	}
+	cfg, err := textSearchConfig(vi.language)
+	if err != nil {
+		return "", nil, err
+	}
+	fn := p.tsQueryFunc(vi.mode)
+	buf, args := new(bytes.Buffer), make([]interface{}, 0, len(vi.fields))
+	buf.WriteString("(")
+	for _, field := range vi.fields {
+		buf.WriteString(fmt.Sprintf("%s @@ %s('%s'::regconfig, %s) OR ", p.tsVector(field, cfg), fn, cfg, variable))
+		args = append(args, vi.query)
+	}
+	buf.Truncate(buf.Len() - 4)
+	buf.WriteString(")")
+	return buf.String(), args, nil
+}
+
+// FullTextRelevance : the sum of ts_rank of the fields
+func (p postgres) FullTextRelevance(fields []string, query, language string, mode SearchMode, perField bool) (string, []interface{}, error) {
+	if len(fields) <= 0 {
+		return "", nil, fmt.Errorf("goloquent: missing fields for full text relevance")
+	}
+	cfg, err := textSearchConfig(language)
+	if err != nil {
+		return "", nil, err
+	}
+	buf, args := new(bytes.Buffer), make([]interface{}, 0, len(fields))
+	buf.WriteString("(")
+	for _, field := range fields {
+		buf.WriteString(fmt.Sprintf("ts_rank(%s, %s('%s'::regconfig, %s)) + ", p.tsVector(field, cfg), p.tsQueryFunc(mode), cfg, variable))
+		args = append(args, query)
+	}
+	buf.Truncate(buf.Len() - 3)
+	buf.WriteString(")")
+	return buf.String(), args, nil
+}
+
+func (p postgres) geoBox(sw, ne datastore.GeoPoint) (string, []interface{}) {
+	return fmt.Sprintf("box(point(%s, %s), point(%s, %s))", variable, variable, variable, variable),
+		[]interface{}{sw.Lng, sw.Lat, ne.Lng, ne.Lat}
+}
+
+// FilterGeo :
+func (p postgres) FilterGeo(f Filter) (string, []interface{}, error) {
+	name := p.Quote(f.Field())
+	switch vi := f.value.(type) {
+	case geoNear:
+		box, args := p.geoBox(boundingBox(vi.point, vi.distance))
+		dist, arg := p.GeoDistance(f.Field(), vi.point)
+		return fmt.Sprintf("(%s <@ %s AND %s <= %s)", name, box, dist, variable),
+			append(append(args, arg...), vi.distance), nil
+	case geoBox:
+		box, args := p.geoBox(vi.sw, vi.ne)
+		return fmt.Sprintf("%s <@ %s", name, box), args, nil
+	}
+	return "", nil, fmt.Errorf("goloquent: invalid geo filter value %v", f.value)
+}
+
+// GeoDistance : the distance between field and the point in meters, using haversine formula
+func (p postgres) GeoDistance(field string, g datastore.GeoPoint) (string, []interface{}) {
+	name := p.Quote(field)
+	lng, lat := name+"[0]", name+"[1]"
+	return fmt.Sprintf("(2 * %v * ASIN(SQRT(POWER(SIN(RADIANS(%s - %s) / 2), 2) + "+
+			"COS(RADIANS(%s)) * COS(RADIANS(%s)) * POWER(SIN(RADIANS(%s - %s) / 2), 2))))",
+			earthRadius, lat, variable, variable, lat, lng, variable),
+		[]interface{}{g.Lat, g.Lat, g.Lng}
+}
+
+// GeoValue :
+func (p postgres) GeoValue(g datastore.GeoPoint) interface{} {
+	return fmt.Sprintf("(%s,%s)",
+		strconv.FormatFloat(g.Lng, 'f', -1, 64),
+		strconv.FormatFloat(g.Lat, 'f', -1, 64))
+}
+
+// FilterArray : the containment operators of native array, the gin index is applicable,
+// the slice without `array` option is stored as jsonb, so the jsonb containment is used instead
+func (p postgres) FilterArray(f Filter) (string, []interface{}, error) {
+	x, err := f.arrayValue()
+	if err != nil {
+		return "", nil, err
+	}
+	if !f.IsNativeArray() {
+		return p.filterJSONArray(f, x)
+	}
+	arg, err := formatArray(x)
+	if err != nil {
+		return "", nil, err
+	}
+	name := p.Quote(f.Field())
+	switch f.operator {
+	case Contains:
+		return fmt.Sprintf("%s @> %s", name, variable), []interface{}{arg}, nil
+	case Overlaps:
+		return fmt.Sprintf("%s && %s", name, variable), []interface{}{arg}, nil
+	case ContainedBy:
+		return fmt.Sprintf("%s <@ %s", name, variable), []interface{}{arg}, nil
+	}
+	return "", nil, fmt.Errorf("goloquent: invalid array filter operator %v", f.operator)
+}
+
+func (p postgres) filterJSONArray(f Filter, x []interface{}) (string, []interface{}, error) {
+	arg, err := marshal(x)
+	if err != nil {
+		return "", nil, err
+	}
+	name := p.Quote(f.Field())
+	switch f.operator {
+	case Contains:
+		return fmt.Sprintf("%s @> %s::jsonb", name, variable), []interface{}{arg}, nil
+	case Overlaps:
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements(%s::jsonb) AS e WHERE %s @> jsonb_build_array(e.value))",
+			variable, name), []interface{}{arg}, nil
+	case ContainedBy:
+		return fmt.Sprintf("%s <@ %s::jsonb", name, variable), []interface{}{arg}, nil
+	}
+	return "", nil, fmt.Errorf("goloquent: invalid array filter operator %v", f.operator)
+}
+
+// ArrayValue : the array literal, eg. `{"a","b"}`
+func (p postgres) ArrayValue(v []interface{}) (interface{}, error) {
+	return formatArray(v)
+}
+
+// TimeValue : the offset is included, so it's same instant for both `timestamp` and `timestamptz` regardless the session time zone
+func (p postgres) TimeValue(t time.Time, precision int) interface{} {
+	return formatTime(t, precision) + "+00:00"
+}
+
 func (p postgres) Value(it interface{}) string {
 	var str string
 	switch vi := it.(type) {
@@ -209,6 +405,9 @@ func (p postgres) DataType(sc Schema) string {
 	if sc.IsUnsigned {
 		buf.WriteString(fmt.Sprintf(" CHECK (%s >= 0)", p.Quote(sc.Name)))
 	}
+	if len(sc.Enum) > 0 {
+		buf.WriteString(" " + p.enumCheck(sc))
+	}
 	if !sc.IsNullable {
 		buf.WriteString(" NOT NULL")
 		t := reflect.TypeOf(sc.DefaultValue)
@@ -219,6 +418,26 @@ func (p postgres) DataType(sc Schema) string {
 	return buf.String()
 }
 
+// enumCheck : enum is the check constraint instead of enum type,
+// so adding the member is able to run in the transaction and the type is not shared by tables
+func (p postgres) enumCheck(sc Schema) string {
+	return fmt.Sprintf("CONSTRAINT %s CHECK (%s IN (%s))",
+		p.Quote(sc.Name+"_enum"), p.Quote(sc.Name), enumList(sc.Enum))
+}
+
+// alterEnum : replace the constraint of existing column, so the new members are added
+// and the constraint is removed when the column is no longer an enum
+func (p postgres) alterEnum(sc Schema, exists bool) string {
+	buf := new(bytes.Buffer)
+	if exists {
+		buf.WriteString(fmt.Sprintf("DROP CONSTRAINT IF EXISTS %s,", p.Quote(sc.Name+"_enum")))
+	}
+	if len(sc.Enum) > 0 {
+		buf.WriteString(fmt.Sprintf("ADD %s,", p.enumCheck(sc)))
+	}
+	return buf.String()
+}
+
 func (p postgres) OnConflictUpdate(table string, cols []string) string {
 	buf := new(bytes.Buffer)
 	buf.WriteString(fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET ", p.Quote(pkColumn)))
@@ -239,9 +458,10 @@ func (p postgres) GetSchema(c Column) []Schema {
 		}
 	}
 
+	// the zero value of `omitempty` field of datastore tag is stored as null
 	sc := Schema{
 		Name:       c.Name(),
-		IsNullable: f.isPtrChild,
+		IsNullable: f.isPtrChild || f.isNullEmpty(),
 	}
 
 	if t.Kind() == reflect.Ptr {
@@ -249,10 +469,10 @@ func (p postgres) GetSchema(c Column) []Schema {
 		if t == typeOfPtrKey {
 			if f.name == keyFieldName {
 				return []Schema{
-					Schema{pkColumn, fmt.Sprintf("varchar(%d)", pkLen), OmitDefault(nil), false, false, false, latin1CharSet},
+					Schema{Name: pkColumn, DataType: fmt.Sprintf("varchar(%d)", pkLen), DefaultValue: OmitDefault(nil), CharSet: latin1CharSet},
 				}
 			}
-			sc.IsIndexed = true
+			sc.IsIndexed = !f.IsNoIndex()
 			sc.DataType = fmt.Sprintf("varchar(%d)", pkLen)
 			sc.CharSet = latin1CharSet
 			return []Schema{sc}
@@ -260,6 +480,17 @@ func (p postgres) GetSchema(c Column) []Schema {
 		t = t.Elem()
 	}
 
+	if codec := codecOf(t); codec != nil {
+		return codec.schema(&p, "postgres", c, sc)
+	}
+
+	if isNativeArray(t, f.tag) {
+		sc.DefaultValue = "{}"
+		sc.DataType = p.arrayType(t.Elem()) + "[]"
+		sc.IsIndexed = !f.IsNoIndex()
+		return []Schema{sc}
+	}
+
 	switch t {
 	case typeOfJSONRawMessage:
 		sc.DefaultValue = OmitDefault(nil)
@@ -270,9 +501,24 @@ func (p postgres) GetSchema(c Column) []Schema {
 	case typeOfDate:
 		sc.DefaultValue = "0001-01-01"
 		sc.DataType = "date"
+	case typeOfDecimal:
+		precision, scale := f.decimalSize()
+		sc.DefaultValue = Decimal{}
+		sc.DataType = fmt.Sprintf("numeric(%d,%d)", precision, scale)
 	case typeOfTime:
+		conf := p.db.timeConfig()
 		sc.DefaultValue = time.Time{}
 		sc.DataType = "timestamp"
+		if conf.isTimestampTZ(f.tag) {
+			sc.DataType = "timestamptz"
+		}
+		if precision := conf.precision(f.tag); precision > 0 {
+			sc.DataType += fmt.Sprintf("(%d)", precision)
+		}
+	case typeOfGeoPoint:
+		sc.DefaultValue = "(0,0)"
+		sc.DataType = "point"
+		sc.IsIndexed = !f.IsNoIndex()
 	case typeOfSoftDelete:
 		sc.DefaultValue = OmitDefault(nil)
 		sc.IsNullable = true
@@ -287,6 +533,10 @@ func (p postgres) GetSchema(c Column) []Schema {
 				sc.DefaultValue = nil
 				sc.DataType = "text"
 			}
+			if values := enumValues(t, f.tag); len(values) > 0 {
+				sc.DefaultValue = values[0]
+				sc.Enum = values
+			}
 		case reflect.Bool:
 			sc.DefaultValue = false
 			sc.DataType = "bool"
@@ -328,6 +578,10 @@ func (p postgres) GetSchema(c Column) []Schema {
 		case reflect.Float32, reflect.Float64:
 			sc.DefaultValue = float64(0)
 			sc.DataType = "real"
+			if f.isDecimal() {
+				precision, scale := f.decimalSize()
+				sc.DataType = fmt.Sprintf("numeric(%d,%d)", precision, scale)
+			}
 		default:
 			sc.DataType = "jsonb"
 		}
@@ -336,6 +590,28 @@ func (p postgres) GetSchema(c Column) []Schema {
 	return []Schema{sc}
 }
 
+// arrayType : the element data type of native array
+func (p postgres) arrayType(t reflect.Type) string {
+	if t == typeOfDecimal {
+		return "numeric"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int8, reflect.Int16, reflect.Uint8, reflect.Uint16:
+		return "smallint"
+	case reflect.Int, reflect.Int32, reflect.Uint, reflect.Uint32:
+		return "integer"
+	case reflect.Int64, reflect.Uint64:
+		return "bigint"
+	case reflect.Float32:
+		return "real"
+	case reflect.Float64:
+		return "double precision"
+	}
+	return "text"
+}
+
 // GetColumns :
 func (p *postgres) GetColumns(table string) (columns []string) {
 	stmt := "SELECT column_name FROM INFORMATION_SCHEMA.columns WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1;"
@@ -348,6 +624,32 @@ func (p *postgres) GetColumns(table string) (columns []string) {
 	return
 }
 
+// columnTypes : the data type of existing columns, eg. `jsonb`, `point`
+func (p *postgres) columnTypes(table string) map[string]string {
+	dataTypes := make(map[string]string)
+	stmt := "SELECT column_name, data_type FROM INFORMATION_SCHEMA.columns WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1;"
+	rows, err := p.db.Query(stmt, table)
+	if err != nil {
+		return dataTypes
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var col, dataType string
+		rows.Scan(&col, &dataType)
+		dataTypes[col] = strings.ToLower(dataType)
+	}
+	return dataTypes
+}
+
+// usingExpr : the conversion of column which the data type is changed, eg. geo point was stored as jsonb
+func (p postgres) usingExpr(sc Schema, dataType string) string {
+	col := p.Quote(sc.Name)
+	if dataType == "jsonb" && sc.isSpatial() {
+		return fmt.Sprintf("point((%s->>'longitude')::float8, (%s->>'latitude')::float8)", col, col)
+	}
+	return ""
+}
+
 // GetIndexes :
 func (p *postgres) GetIndexes(table string) (idxs []string) {
 	stmt := "SELECT indexname FROM pg_indexes WHERE schemaname = CURRENT_SCHEMA() AND tablename = $1;"
@@ -366,6 +668,40 @@ func (p *postgres) HasTable(table string) bool {
 	return count > 0
 }
 
+// AddJSONIndex : index the json path using expression index for comparison and gin index for containment
+func (p *postgres) AddJSONIndex(table, field, path string) error {
+	name := jsonIndexName(table, field, path)
+	expr := p.SplitJSON(jsonPathColumn(field, path))
+	stmts := []string{
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s ((%s));",
+			p.Quote(name+"_idx"), p.GetTable(table), expr),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING GIN ((%s));",
+			p.Quote(name+"_gin"), p.GetTable(table), expr),
+	}
+	for _, s := range stmts {
+		if err := p.db.execStmt(&stmt{
+			statement: bytes.NewBufferString(s),
+			crud:      OpMigrate,
+			table:     table,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddFullTextIndex :
+func (p *postgres) AddFullTextIndex(table, field, language string) error {
+	cfg, err := textSearchConfig(language)
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteString(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING GIN (%s);",
+		p.Quote(fullTextIndexName(table, field)), p.GetTable(table), p.tsVector(field, cfg)))
+	return p.db.execStmt(&stmt{statement: buf, crud: OpMigrate, table: table})
+}
+
 func (p *postgres) HasIndex(table, idx string) bool {
 	var count int
 	p.db.QueryRow("SELECT count(*) FROM pg_indexes WHERE tablename = $1 AND indexname = $2 AND schemaname = CURRENT_SCHEMA()", table, idx).Scan(&count)
@@ -418,8 +754,8 @@ func (p *postgres) CreateTable(table string, columns []Column) error {
 
 			if ss.IsIndexed {
 				idx := fmt.Sprintf("%s_%s_%s", table, ss.Name, "Idx")
-				stmt := fmt.Sprintf("CREATE INDEX %s ON %s (%s);",
-					p.Quote(idx), p.GetTable(table), p.Quote(ss.Name))
+				stmt := fmt.Sprintf("CREATE INDEX %s ON %s %s;",
+					p.Quote(idx), p.GetTable(table), p.indexColumn(ss))
 				idxs = append(idxs, stmt)
 			}
 		}
@@ -440,10 +776,70 @@ func (p *postgres) CreateTable(table string, columns []Column) error {
 	return tx.Commit()
 }
 
+// indexColumn will use pattern operator class for parent key column,
+// so the prefix matching of descendant query is able to use the index,
+// point column will use GiST index and array column will use GIN index
+func (p *postgres) indexColumn(sc Schema) string {
+	switch {
+	case sc.Name == parentColumn:
+		return "(" + p.Quote(sc.Name) + " varchar_pattern_ops)"
+	case sc.isSpatial():
+		return "USING GIST (" + p.Quote(sc.Name) + ")"
+	case sc.isArray():
+		return "USING GIN (" + p.Quote(sc.Name) + ")"
+	}
+	return "(" + p.Quote(sc.Name) + ")"
+}
+
+// convertArrayColumn will convert the jsonb column to native array column, the subquery is not allowed in
+// `USING` of `ALTER COLUMN TYPE`, so the elements are backfilled into the new column and swapped with the jsonb column
+func (p *postgres) convertArrayColumn(table string, sc Schema) error {
+	tb, col, tmp := p.GetTable(table), p.Quote(sc.Name), p.Quote(sc.Name+"_array")
+	fallback := "'{}'"
+	if sc.IsNullable {
+		fallback = "NULL"
+	}
+	// the previous default is dropped together with the jsonb column
+	for _, str := range []string{
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s NULL;", tb, tmp, sc.DataType),
+		fmt.Sprintf("UPDATE %s SET %s = CASE WHEN jsonb_typeof(%s) = 'array' THEN ARRAY(SELECT jsonb_array_elements_text(%s))::%s ELSE %s END;",
+			tb, tmp, col, col, sc.DataType, fallback),
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", tb, col),
+		fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", tb, tmp, col),
+	} {
+		if err := p.db.execStmt(&stmt{statement: bytes.NewBufferString(str), crud: OpMigrate, table: table}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *postgres) AlterTable(table string, columns []Column, unsafe bool) error {
+	// the slice was stored as jsonb, it cannot be casted to array directly
+	dataTypes := p.columnTypes(table)
+	for _, c := range columns {
+		for _, ss := range p.GetSchema(c) {
+			if dt, isOk := dataTypes[ss.Name]; isOk && ss.isArray() && dt == "jsonb" {
+				if err := p.convertArrayColumn(table, ss); err != nil {
+					return err
+				}
+				dataTypes[ss.Name] = "array"
+			}
+		}
+	}
+
 	cols := newDictionary(p.GetColumns(table))
+	hasParent := cols.has(parentColumn)
+	hasOverflow := false
+	for _, c := range columns {
+		if c.Name() == overflowColumn {
+			hasOverflow = true
+		}
+	}
 	idxs := newDictionary(p.GetIndexes(table))
 	idxs.delete(fmt.Sprintf("%s_pkey", table))
+	// spatial and array index are not the default btree index
+	indexes := make([]string, 0)
 	buf := new(bytes.Buffer)
 	buf.WriteString(fmt.Sprintf("ALTER TABLE %s ", p.GetTable(table)))
 	for _, c := range columns {
@@ -460,7 +856,13 @@ func (p *postgres) AlterTable(table string, columns []Column, unsafe bool) error
 				buf.WriteString(",")
 			} else {
 				prefix := fmt.Sprintf("ALTER COLUMN %s", p.Quote(ss.Name))
-				buf.WriteString(fmt.Sprintf("%s TYPE %s", prefix, ss.DataType))
+				if using := p.usingExpr(ss, dataTypes[ss.Name]); using != "" {
+					// the default of previous data type cannot be casted, it's reset after the conversion
+					buf.WriteString(prefix + " DROP DEFAULT,")
+					buf.WriteString(fmt.Sprintf("%s TYPE %s USING %s", prefix, ss.DataType, using))
+				} else {
+					buf.WriteString(fmt.Sprintf("%s TYPE %s", prefix, ss.DataType))
+				}
 				buf.WriteString(",")
 				if !ss.IsNullable {
 					buf.WriteString(prefix + " SET NOT NULL,")
@@ -471,10 +873,15 @@ func (p *postgres) AlterTable(table string, columns []Column, unsafe bool) error
 				}
 			}
 
+			buf.WriteString(p.alterEnum(ss, cols.has(ss.Name)))
+
 			if ss.IsIndexed {
 				idx := fmt.Sprintf("%s_%s_%s", table, ss.Name, "idx")
 				if idxs.has(idx) {
 					idxs.delete(idx)
+				} else if ss.isSpatial() || ss.isArray() {
+					indexes = append(indexes, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s %s;",
+						p.Quote(idx), p.GetTable(table), p.indexColumn(ss)))
 				} else {
 
 					// buf.WriteString(fmt.Sprintf(
@@ -487,16 +894,58 @@ func (p *postgres) AlterTable(table string, columns []Column, unsafe bool) error
 		}
 	}
 
-	for _, col := range cols.keys() {
-		buf.WriteString(fmt.Sprintf(" DROP COLUMN %s,", p.Quote(col)))
+	// schemaless table will keep the columns which not declare in the entity
+	if !hasOverflow {
+		for _, col := range cols.keys() {
+			buf.WriteString(fmt.Sprintf(" DROP COLUMN %s,", p.Quote(col)))
+		}
 	}
 
 	buf.Truncate(buf.Len() - 1)
 	buf.WriteString(";")
 
 	log.Println(idxs.keys())
-	return p.db.execStmt(&stmt{
+	if err := p.db.execStmt(&stmt{
+		statement: buf,
+		crud:      OpMigrate,
+		table:     table,
+	}); err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		if err := p.db.execStmt(&stmt{
+			statement: bytes.NewBufferString(idx),
+			crud:      OpMigrate,
+			table:     table,
+		}); err != nil {
+			return err
+		}
+	}
+	if hasParent {
+		return nil
+	}
+
+	// backfill the parent key path of the existing records
+	pk, parent := p.Quote(pkColumn), p.Quote(parentColumn)
+	buf.Reset()
+	buf.WriteString(fmt.Sprintf("UPDATE %s SET %s = regexp_replace(%s, '%s[^%s]*$', '')",
+		p.GetTable(table), parent, pk, keyDelimeter, keyDelimeter))
+	buf.WriteString(fmt.Sprintf(" WHERE %s LIKE '%%%s%%';", pk, keyDelimeter))
+	if err := p.db.execStmt(&stmt{
 		statement: buf,
+		crud:      OpMigrate,
+		table:     table,
+	}); err != nil {
+		return err
+	}
+
+	// prepared statement is not allow to have multiple commands
+	return p.db.execStmt(&stmt{
+		statement: bytes.NewBufferString(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s %s;",
+			p.Quote(fmt.Sprintf("%s_%s_%s", table, parentColumn, "idx")),
+			p.GetTable(table), p.indexColumn(Schema{Name: parentColumn}))),
+		crud:  OpMigrate,
+		table: table,
 	})
 
 	// for _, idx := range idxs.keys() {
@@ -543,3 +992,13 @@ func (p *postgres) ReplaceInto(src, dst string) error {
 		statement: buf,
 	})
 }
+
+// Explain :
+func (p *postgres) Explain(s *Stmt) (*Plan, error) {
+	var b []byte
+	query := "EXPLAIN (FORMAT JSON) " + strings.TrimSuffix(s.Raw(), ";")
+	if err := p.db.QueryRow(query, s.arguments...).Scan(&b); err != nil {
+		return nil, fmt.Errorf("goloquent: %v", err)
+	}
+	return parsePostgresPlan(b)
+}
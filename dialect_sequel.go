@@ -9,6 +9,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"cloud.google.com/go/datastore"
 )
 
 func checkMultiPtr(v reflect.Value) (isPtr bool, t reflect.Type) {
@@ -34,11 +36,21 @@ type sqlExtra interface {
 
 // sequel :
 type sequel struct {
-	dbName string
-	db     Client
+	dbName  string
+	version string
+	db      Client
 }
 
-var _ Dialect = new(sequel)
+var (
+	_ Dialect           = new(sequel)
+	_ GeoDialect        = new(sequel)
+	_ JSONIndexDialect  = new(sequel)
+	_ JSONUpdateDialect = new(sequel)
+	_ FullTextDialect   = new(sequel)
+	_ TimeDialect       = new(sequel)
+	_ ArrayDialect      = new(sequel)
+	_ ExplainDialect    = new(sequel)
+)
 
 func init() {
 	RegisterDialect("common", new(sequel))
@@ -47,6 +59,7 @@ func init() {
 // SetDB :
 func (s *sequel) SetDB(db Client) {
 	s.db = db
+	s.version = ""
 }
 
 func (s *sequel) Open(conf Config) (*sql.DB, error) {
@@ -92,7 +105,7 @@ func (s *sequel) Bind(uint) string {
 }
 
 func (s *sequel) SplitJSON(name string) string {
-	paths := strings.SplitN(name, ">", 2)
+	paths := strings.SplitN(name, jsonDelimeter, 2)
 	if len(paths) <= 1 {
 		return s.Quote(paths[0])
 	}
@@ -110,11 +123,23 @@ func (s sequel) JSONMarshal(v interface{}) (b json.RawMessage) {
 	case string:
 		b = json.RawMessage(fmt.Sprintf("%q", vi))
 	default:
+		// the array and object must be marshalled, otherwise `[a b]` is not a valid json
+		if bb, err := json.Marshal(vi); err == nil {
+			return json.RawMessage(bb)
+		}
 		b = json.RawMessage(fmt.Sprintf("%v", vi))
 	}
 	return
 }
 
+// jsonText : the value is compared with the unquoted text of json path, eg. `true` instead of `1`
+func (s sequel) jsonText(v interface{}) interface{} {
+	if str, isOk := v.(string); isOk {
+		return str
+	}
+	return string(s.JSONMarshal(v))
+}
+
 func (s sequel) FilterJSON(f Filter) (string, []interface{}, error) {
 	vv, err := f.Interface()
 	if err != nil {
@@ -134,6 +159,10 @@ func (s sequel) FilterJSON(f Filter) (string, []interface{}, error) {
 		buf.WriteString(fmt.Sprintf("(%s) > %s", name, variable))
 	case GreaterEqual:
 		buf.WriteString(fmt.Sprintf("(%s) >= %s", name, variable))
+	case LessThan:
+		buf.WriteString(fmt.Sprintf("(%s) < %s", name, variable))
+	case LessEqual:
+		buf.WriteString(fmt.Sprintf("(%s) <= %s", name, variable))
 	case In:
 		x, isOk := vv.([]interface{})
 		if !isOk {
@@ -142,6 +171,17 @@ func (s sequel) FilterJSON(f Filter) (string, []interface{}, error) {
 		if len(x) <= 0 {
 			return "", nil, fmt.Errorf(`goloquent: value for "In" operator cannot be empty`)
 		}
+		// same expression with the generated column, so the json index is applicable
+		if f.isIndexed {
+			buf.WriteString(fmt.Sprintf("(%s) IN (", name))
+			for i := 0; i < len(x); i++ {
+				buf.WriteString(variable + ",")
+				args = append(args, s.jsonText(x[i]))
+			}
+			buf.Truncate(buf.Len() - 1)
+			buf.WriteString(")")
+			return buf.String(), args, nil
+		}
 		buf.WriteString("(")
 		for i := 0; i < len(x); i++ {
 			buf.WriteString(fmt.Sprintf("JSON_CONTAINS(%s, %s) OR ", name, variable))
@@ -158,12 +198,22 @@ func (s sequel) FilterJSON(f Filter) (string, []interface{}, error) {
 		if len(x) <= 0 {
 			return "", nil, fmt.Errorf(`goloquent: value for "NotIn" operator cannot be empty`)
 		}
+		if f.isIndexed {
+			buf.WriteString(fmt.Sprintf("(%s) NOT IN (", name))
+			for i := 0; i < len(x); i++ {
+				buf.WriteString(variable + ",")
+				args = append(args, s.jsonText(x[i]))
+			}
+			buf.Truncate(buf.Len() - 1)
+			buf.WriteString(")")
+			return buf.String(), args, nil
+		}
 		buf.WriteString("(")
 		for i := 0; i < len(x); i++ {
-			buf.WriteString(fmt.Sprintf("%s <> %s AND ", name, variable))
+			buf.WriteString(fmt.Sprintf("NOT JSON_CONTAINS(%s, %s) AND ", name, variable))
 			args = append(args, s.JSONMarshal(x[i]))
 		}
-		buf.Truncate(buf.Len() - 4)
+		buf.Truncate(buf.Len() - 5)
 		buf.WriteString(")")
 		return buf.String(), args, nil
 	case ContainAny:
@@ -198,6 +248,215 @@ func (s sequel) FilterJSON(f Filter) (string, []interface{}, error) {
 	return buf.String(), args, nil
 }
 
+// UpdateJSON : wrap the expression with the partial update function of json path
+func (s sequel) UpdateJSON(expr string, args []interface{}, paths []string, u JSONUpdate) (string, []interface{}, error) {
+	path := mysqlJSONPath(paths)
+	if u.op == jsonRemove {
+		return fmt.Sprintf("JSON_REMOVE(%s, %s)", expr, variable), append(args, path), nil
+	}
+	b, err := u.Value()
+	if err != nil {
+		return "", nil, err
+	}
+	fn := "JSON_SET"
+	if u.op == jsonArrayAppend {
+		fn = "JSON_ARRAY_APPEND"
+	}
+	// json from binary string is not allowed, so it must be string
+	return fmt.Sprintf("%s(%s, %s, CAST(%s AS JSON))", fn, expr, variable, variable),
+		append(args, path, string(b)), nil
+}
+
+func (s sequel) searchMode(mode SearchMode) string {
+	if mode == BooleanMode {
+		return "BOOLEAN"
+	}
+	return "NATURAL LANGUAGE"
+}
+
+// FilterFullText : the column list of MATCH must be same as the fulltext index,
+// so the fields are only matching individually when every field has its own index
+func (s sequel) FilterFullText(f Filter) (string, []interface{}, error) {
+	vi, isOk := f.value.(fullTextSearch)
+	if !isOk {
+		return "", nil, fmt.Errorf("goloquent: invalid full text search value %v", f.value)
+	}
+	mode := s.searchMode(vi.mode)
+	if !vi.perField {
+		cols := make([]string, len(vi.fields))
+		for i, field := range vi.fields {
+			cols[i] = s.Quote(field)
+		}
+		return fmt.Sprintf("MATCH(%s) AGAINST(%s IN %s MODE)", strings.Join(cols, ","), variable, mode),
+			[]interface{}{vi.query}, nil
+	}
+	buf, args := new(bytes.Buffer), make([]interface{}, 0, len(vi.fields))
+	buf.WriteString("(")
+	for _, field := range vi.fields {
+		buf.WriteString(fmt.Sprintf("MATCH(%s) AGAINST(%s IN %s MODE) OR ", s.Quote(field), variable, mode))
+		args = append(args, vi.query)
+	}
+	buf.Truncate(buf.Len() - 4)
+	buf.WriteString(")")
+	return buf.String(), args, nil
+}
+
+// FullTextRelevance : the sum of relevance score of the fields, the column list of MATCH must be same as the filter
+func (s sequel) FullTextRelevance(fields []string, query, language string, mode SearchMode, perField bool) (string, []interface{}, error) {
+	if len(fields) <= 0 {
+		return "", nil, fmt.Errorf("goloquent: missing fields for full text relevance")
+	}
+	m := s.searchMode(mode)
+	if !perField {
+		cols := make([]string, len(fields))
+		for i, field := range fields {
+			cols[i] = s.Quote(field)
+		}
+		return fmt.Sprintf("MATCH(%s) AGAINST(%s IN %s MODE)", strings.Join(cols, ","), variable, m),
+			[]interface{}{query}, nil
+	}
+	buf, args := new(bytes.Buffer), make([]interface{}, 0, len(fields))
+	buf.WriteString("(")
+	for _, field := range fields {
+		buf.WriteString(fmt.Sprintf("MATCH(%s) AGAINST(%s IN %s MODE) + ", s.Quote(field), variable, m))
+		args = append(args, query)
+	}
+	buf.Truncate(buf.Len() - 3)
+	buf.WriteString(")")
+	return buf.String(), args, nil
+}
+
+// serverVersion : the version of server is cached, eg. `8.0.35`
+func (s *sequel) serverVersion() string {
+	if s.version == "" && s.db.sqlCommon != nil {
+		s.db.QueryRow("SELECT VERSION();").Scan(&s.version)
+	}
+	return s.version
+}
+
+// hasSpatial : the geo point is stored as `POINT SRID 4326` which requires mysql 8.0 or above,
+// otherwise it's stored as json, the server is assumed to be 8.0 when the version is unknown
+func (s *sequel) hasSpatial() bool {
+	version := s.serverVersion()
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return false
+	}
+	ver := versionRgx.FindString(version)
+	return ver == "" || compareVersion(ver, spatialVersion) <= 0
+}
+
+func (s sequel) geomFromText(wkt string) (string, interface{}) {
+	return fmt.Sprintf("ST_GeomFromText(%s, %d, 'axis-order=long-lat')", variable, geoSRID), wkt
+}
+
+func wktPoint(g datastore.GeoPoint) string {
+	return fmt.Sprintf("POINT(%s %s)",
+		strconv.FormatFloat(g.Lng, 'f', -1, 64),
+		strconv.FormatFloat(g.Lat, 'f', -1, 64))
+}
+
+func wktBox(sw, ne datastore.GeoPoint) string {
+	x1, y1 := strconv.FormatFloat(sw.Lng, 'f', -1, 64), strconv.FormatFloat(sw.Lat, 'f', -1, 64)
+	x2, y2 := strconv.FormatFloat(ne.Lng, 'f', -1, 64), strconv.FormatFloat(ne.Lat, 'f', -1, 64)
+	return fmt.Sprintf("POLYGON((%s %s,%s %s,%s %s,%s %s,%s %s))",
+		x1, y1, x2, y1, x2, y2, x1, y2, x1, y1)
+}
+
+// FilterGeo :
+func (s *sequel) FilterGeo(f Filter) (string, []interface{}, error) {
+	if !s.hasSpatial() {
+		return s.filterGeoJSON(f)
+	}
+	name := s.Quote(f.Field())
+	switch vi := f.value.(type) {
+	case geoNear:
+		sw, ne := boundingBox(vi.point, vi.distance)
+		box, arg := s.geomFromText(wktBox(sw, ne))
+		dist, args := s.GeoDistance(f.Field(), vi.point)
+		return fmt.Sprintf("(MBRContains(%s, %s) AND %s <= %s)", box, name, dist, variable),
+			append(append([]interface{}{arg}, args...), vi.distance), nil
+	case geoBox:
+		box, arg := s.geomFromText(wktBox(vi.sw, vi.ne))
+		return fmt.Sprintf("MBRContains(%s, %s)", box, name), []interface{}{arg}, nil
+	}
+	return "", nil, fmt.Errorf("goloquent: invalid geo filter value %v", f.value)
+}
+
+// geoJSONPoint : the longitude and latitude of geo point which stored as json
+func (s *sequel) geoJSONPoint(field string) (string, string) {
+	name := s.Quote(field)
+	return fmt.Sprintf("CAST(%s->>'$.longitude' AS DECIMAL(11,8))", name),
+		fmt.Sprintf("CAST(%s->>'$.latitude' AS DECIMAL(10,8))", name)
+}
+
+// filterGeoJSON : the geo point is stored as json before mysql 8.0, the filter is unable to use the index
+func (s *sequel) filterGeoJSON(f Filter) (string, []interface{}, error) {
+	lng, lat := s.geoJSONPoint(f.Field())
+	box := fmt.Sprintf("%s BETWEEN %s AND %s AND %s BETWEEN %s AND %s", lng, variable, variable, lat, variable, variable)
+	switch vi := f.value.(type) {
+	case geoNear:
+		sw, ne := boundingBox(vi.point, vi.distance)
+		dist, args := s.GeoDistance(f.Field(), vi.point)
+		return fmt.Sprintf("(%s AND %s <= %s)", box, dist, variable),
+			append(append([]interface{}{sw.Lng, ne.Lng, sw.Lat, ne.Lat}, args...), vi.distance), nil
+	case geoBox:
+		return fmt.Sprintf("(%s)", box), []interface{}{vi.sw.Lng, vi.ne.Lng, vi.sw.Lat, vi.ne.Lat}, nil
+	}
+	return "", nil, fmt.Errorf("goloquent: invalid geo filter value %v", f.value)
+}
+
+// GeoDistance : the distance between field and the point in meters
+func (s *sequel) GeoDistance(field string, g datastore.GeoPoint) (string, []interface{}) {
+	if !s.hasSpatial() {
+		lng, lat := s.geoJSONPoint(field)
+		return fmt.Sprintf("ST_Distance_Sphere(POINT(%s, %s), POINT(%s, %s))", lng, lat, variable, variable),
+			[]interface{}{g.Lng, g.Lat}
+	}
+	point, arg := s.geomFromText(wktPoint(g))
+	return fmt.Sprintf("ST_Distance_Sphere(%s, %s)", s.Quote(field), point), []interface{}{arg}
+}
+
+// GeoValue :
+func (s *sequel) GeoValue(g datastore.GeoPoint) interface{} {
+	if !s.hasSpatial() {
+		b, _ := json.Marshal(geoLocation{g.Lat, g.Lng})
+		return b2s(b)
+	}
+	return geoToWKB(g)
+}
+
+// FilterArray : the array is stored as json, `JSON_OVERLAPS` requires mysql 8.0.17 or above
+func (s sequel) FilterArray(f Filter) (string, []interface{}, error) {
+	x, err := f.arrayValue()
+	if err != nil {
+		return "", nil, err
+	}
+	arg, err := marshal(x)
+	if err != nil {
+		return "", nil, err
+	}
+	name := s.Quote(f.Field())
+	switch f.operator {
+	case Contains:
+		return fmt.Sprintf("JSON_CONTAINS(%s, %s)", name, variable), []interface{}{arg}, nil
+	case Overlaps:
+		return fmt.Sprintf("JSON_OVERLAPS(%s, %s)", name, variable), []interface{}{arg}, nil
+	case ContainedBy:
+		return fmt.Sprintf("JSON_CONTAINS(%s, %s)", variable, name), []interface{}{arg}, nil
+	}
+	return "", nil, fmt.Errorf("goloquent: invalid array filter operator %v", f.operator)
+}
+
+// ArrayValue : the array is stored as json
+func (s sequel) ArrayValue(v []interface{}) (interface{}, error) {
+	return marshal(v)
+}
+
+// TimeValue : the time in UTC, the fractional seconds which exceed the precision are truncated instead of rounded
+func (s sequel) TimeValue(t time.Time, precision int) interface{} {
+	return formatTime(t, precision)
+}
+
 func (s *sequel) Value(it interface{}) string {
 	var str string
 	switch vi := it.(type) {
@@ -274,15 +533,16 @@ func (s *sequel) GetSchema(c Column) []Schema {
 		}
 	}
 
+	// the zero value of `omitempty` field of datastore tag is stored as null
 	sc := Schema{
 		Name:       c.Name(),
-		IsNullable: f.isPtrChild,
+		IsNullable: f.isPtrChild || f.isNullEmpty(),
 		IsIndexed:  f.IsIndex(),
 	}
 	if t.Kind() == reflect.Ptr {
 		sc.IsNullable = true
 		if t == typeOfPtrKey {
-			sc.IsIndexed = true
+			sc.IsIndexed = !f.IsNoIndex()
 			sc.DataType = fmt.Sprintf("varchar(%d)", pkLen)
 			sc.CharSet = latin1CharSet
 			if f.name == keyFieldName {
@@ -295,6 +555,10 @@ func (s *sequel) GetSchema(c Column) []Schema {
 		t = t.Elem()
 	}
 
+	if codec := codecOf(t); codec != nil {
+		return codec.schema(s, "mysql", c, sc)
+	}
+
 	switch t {
 	case typeOfJSONRawMessage:
 		sc.DefaultValue = OmitDefault(nil)
@@ -305,9 +569,26 @@ func (s *sequel) GetSchema(c Column) []Schema {
 	case typeOfDate:
 		sc.DefaultValue = "0001-01-01"
 		sc.DataType = "date"
+	case typeOfDecimal:
+		precision, scale := f.decimalSize()
+		sc.DefaultValue = Decimal{}
+		sc.DataType = fmt.Sprintf("decimal(%d,%d)", precision, scale)
+		sc.IsUnsigned = f.IsUnsigned()
 	case typeOfTime:
 		sc.DefaultValue = time.Time{}
 		sc.DataType = "datetime"
+		if precision := s.db.timeConfig().precision(f.tag); precision > 0 {
+			sc.DataType = fmt.Sprintf("datetime(%d)", precision)
+		}
+	case typeOfGeoPoint:
+		sc.DefaultValue = OmitDefault(nil)
+		if !s.hasSpatial() {
+			sc.DataType = "json"
+			sc.IsIndexed = false
+			break
+		}
+		sc.DataType = fmt.Sprintf("point SRID %d", geoSRID)
+		sc.IsIndexed = !sc.IsNullable && !f.IsNoIndex()
 	case typeOfSoftDelete:
 		sc.DefaultValue = OmitDefault(nil)
 		sc.IsNullable = true
@@ -325,6 +606,12 @@ func (s *sequel) GetSchema(c Column) []Schema {
 			if f.Get("datatype") != "" {
 				sc.DataType = f.Get("datatype")
 			}
+			// the new members are added by `MODIFY` column
+			if values := enumValues(t, f.tag); len(values) > 0 {
+				sc.DefaultValue = values[0]
+				sc.DataType = fmt.Sprintf("enum(%s)", enumList(values))
+				sc.Enum = values
+			}
 			sc.CharSet = utf8mb4CharSet
 			charset := f.Get("charset")
 			if charset != "" {
@@ -376,6 +663,10 @@ func (s *sequel) GetSchema(c Column) []Schema {
 			sc.DefaultValue = float64(0)
 			sc.DataType = "double"
 			sc.IsUnsigned = f.IsUnsigned()
+			if f.isDecimal() {
+				precision, scale := f.decimalSize()
+				sc.DataType = fmt.Sprintf("decimal(%d,%d)", precision, scale)
+			}
 		case reflect.Slice, reflect.Array:
 			sc.DefaultValue = OmitDefault(nil)
 			sc.DataType = "json"
@@ -400,6 +691,23 @@ func (s *sequel) GetColumns(table string) (columns []string) {
 	return
 }
 
+// columnTypes : the data type of existing columns, eg. `json`, `point`
+func (s *sequel) columnTypes(table string) map[string]string {
+	dataTypes := make(map[string]string)
+	stmt := "SELECT COLUMN_NAME, DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?;"
+	rows, err := s.db.Query(stmt, s.CurrentDB(), table)
+	if err != nil {
+		return dataTypes
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var col, dataType string
+		rows.Scan(&col, &dataType)
+		dataTypes[col] = strings.ToLower(dataType)
+	}
+	return dataTypes
+}
+
 // GetIndexes :
 func (s *sequel) GetIndexes(table string) (idxs []string) {
 	stmt := "SELECT DISTINCT INDEX_NAME FROM INFORMATION_SCHEMA.STATISTICS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME <> ?;"
@@ -418,9 +726,42 @@ func (s *sequel) HasTable(table string) bool {
 	return count > 0
 }
 
+// AddJSONIndex : index the json path using virtual generated column
+func (s *sequel) AddJSONIndex(table, field, path string) error {
+	col := jsonPathColumn(field, path)
+	idx := jsonIndexName(table, field, path) + "_idx"
+	buf := new(bytes.Buffer)
+	buf.WriteString(fmt.Sprintf("ALTER TABLE %s ", s.GetTable(table)))
+	if !newDictionary(s.GetColumns(table)).has(col) {
+		buf.WriteString(fmt.Sprintf("ADD COLUMN %s VARCHAR(191) CHARACTER SET %s COLLATE %s GENERATED ALWAYS AS (%s) VIRTUAL,",
+			s.Quote(col), s.Quote("utf8mb4"), s.Quote("utf8mb4_bin"), s.SplitJSON(col)))
+	}
+	if !s.HasIndex(table, idx) {
+		buf.WriteString(fmt.Sprintf("ADD INDEX %s (%s),", s.Quote(idx), s.Quote(col)))
+	}
+	if buf.Bytes()[buf.Len()-1] != ',' {
+		return nil
+	}
+	buf.Truncate(buf.Len() - 1)
+	buf.WriteString(";")
+	return s.db.execStmt(&stmt{statement: buf, crud: OpMigrate, table: table})
+}
+
+// AddFullTextIndex :
+func (s *sequel) AddFullTextIndex(table, field, language string) error {
+	idx := fullTextIndexName(table, field)
+	if s.HasIndex(table, idx) {
+		return nil
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteString(fmt.Sprintf("ALTER TABLE %s ADD FULLTEXT INDEX %s (%s);",
+		s.GetTable(table), s.Quote(idx), s.Quote(field)))
+	return s.db.execStmt(&stmt{statement: buf, crud: OpMigrate, table: table})
+}
+
 func (s *sequel) HasIndex(table, idx string) bool {
 	var count int
-	s.db.QueryRow("SELECT count(*) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME = ?", s.CurrentDB(), table, idx).Scan(&count)
+	s.db.QueryRow("SELECT count(*) FROM INFORMATION_SCHEMA.STATISTICS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME = ?", s.CurrentDB(), table, idx).Scan(&count)
 	return count > 0
 }
 
@@ -450,3 +791,13 @@ func (s sequel) UpdateWithLimit() bool {
 func (s sequel) ReplaceInto(src, dst string) error {
 	return nil
 }
+
+// Explain :
+func (s *sequel) Explain(ss *Stmt) (*Plan, error) {
+	var b []byte
+	query := "EXPLAIN FORMAT=JSON " + strings.TrimSuffix(ss.Raw(), ";")
+	if err := s.db.QueryRow(query, ss.arguments...).Scan(&b); err != nil {
+		return nil, fmt.Errorf("goloquent: %v", err)
+	}
+	return parseMySQLPlan(b)
+}
@@ -0,0 +1,85 @@
+package goloquent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/si3nloong/goloquent/expr"
+)
+
+// testBasicDialect only implements the required methods of `Dialect`, the optional capabilities are hidden
+type testBasicDialect struct {
+	Dialect
+}
+
+func TestOptionalDialect(t *testing.T) {
+	type Store struct {
+		Key      *datastore.Key `goloquent:"__key__"`
+		Location datastore.GeoPoint
+	}
+
+	db := newFakeDB(t, testBasicDialect{new(sequel)})
+	point := datastore.GeoPoint{Lat: 3.139003, Lng: 101.686855}
+	for _, q := range []*Query{
+		db.WhereNear("Location", point, 1000),
+		db.NewQuery().OrderBy(expr.Distance{Name: "Location", Lat: point.Lat, Lng: point.Lng}),
+	} {
+		if _, err := q.ToSQL(OpGet, &[]Store{}); err == nil || !strings.Contains(err.Error(), "doesn't support geo") {
+			t.Fatalf("unsupported geo capability should return error, %v", err)
+		}
+	}
+
+	// the geo point is stored as json
+	props, err := SaveStruct(&Store{Location: point})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := newBuilder(db.NewQuery()).propertyValue(props["Location"]); err != nil ||
+		!strings.Contains(fmt.Sprintf("%s", v), `"latitude":3.139003`) {
+		t.Fatalf("unexpected value of geo point, %v", v)
+	}
+
+	if err := db.Table("Store").AddJSONIndex("Address", "region.regionCode"); err == nil ||
+		!strings.Contains(err.Error(), "doesn't support json index") {
+		t.Fatalf("unsupported json index capability should return error, %v", err)
+	}
+
+	if err := db.Table("Store").Update(map[string]interface{}{"Address>region>regionCode": "MY"}); err == nil ||
+		!strings.Contains(err.Error(), "doesn't support partial json update") {
+		t.Fatalf("unsupported partial json update capability should return error, %v", err)
+	}
+
+	for _, q := range []*Query{
+		db.Match([]string{"Name"}, "hello", NaturalLanguageMode),
+		db.NewQuery().OrderBy(expr.Relevance{Fields: []string{"Name"}, Query: "hello"}),
+	} {
+		if _, err := q.ToSQL(OpGet, &[]Store{}); err == nil || !strings.Contains(err.Error(), "doesn't support full text") {
+			t.Fatalf("unsupported full text capability should return error, %v", err)
+		}
+	}
+
+	// the time is formatted with the full precision
+	dt := time.Date(2020, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	props, err = SaveStruct(&struct {
+		Key       *datastore.Key `goloquent:"__key__"`
+		CreatedAt time.Time
+	}{CreatedAt: dt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := newBuilder(db.NewQuery()).propertyValue(props["CreatedAt"]); err != nil || v != "2020-01-02 03:04:05.123456" {
+		t.Fatalf("unexpected value of time, %v", v)
+	}
+
+	if _, err := db.WhereContains("Tags", []string{"a"}).ToSQL(OpGet, &[]Store{}); err == nil ||
+		!strings.Contains(err.Error(), "doesn't support array filter") {
+		t.Fatalf("unsupported array capability should return error, %v", err)
+	}
+
+	if _, err := db.Table("Store").Explain(); err == nil || !strings.Contains(err.Error(), "doesn't support explain") {
+		t.Fatalf("unsupported explain capability should return error, %v", err)
+	}
+}
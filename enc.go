@@ -17,6 +17,11 @@ type Registry struct {
 	sync.Mutex
 	typeEncoders map[reflect.Type]encodeFunc
 	kindEncoders map[reflect.Kind]encodeFunc
+	// codecMu guards codecs and derived, the lookup of codec is on every encode and decode,
+	// so it's only read locked
+	codecMu sync.RWMutex
+	codecs  map[reflect.Type]*Codec
+	derived map[reflect.Type]*Codec
 }
 
 func init() {
@@ -28,6 +33,8 @@ func NewRegistry() *Registry {
 	return &Registry{
 		typeEncoders: make(map[reflect.Type]encodeFunc),
 		kindEncoders: make(map[reflect.Kind]encodeFunc),
+		codecs:       make(map[reflect.Type]*Codec),
+		derived:      make(map[reflect.Type]*Codec),
 	}
 }
 
@@ -66,6 +73,9 @@ func (r *Registry) SetKindEncoder(k reflect.Kind, f encodeFunc) {
 }
 
 func (r *Registry) EncodeValue(v reflect.Value) (interface{}, error) {
+	if c, isOk := r.Codec(v.Type()); isOk {
+		return c.Encode(v.Interface())
+	}
 	if encoder, isOk := r.typeEncoders[v.Type()]; isOk {
 		return encoder(v)
 	}
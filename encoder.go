@@ -43,18 +43,18 @@ func normalize(f field, it interface{}) ([]Property, error) {
 			}
 
 			for k, vv := range vals {
-				props = append(props, Property{[]string{k}, f.typeOf, vv})
+				props = append(props, Property{[]string{k}, f.typeOf, vv, f.tag})
 			}
 			return props, nil
 		}
 
 		for k, vv := range flatMap(it.(map[string]interface{})) {
-			props = append(props, Property{[]string{f.name, k}, f.typeOf, vv})
+			props = append(props, Property{[]string{f.name, k}, f.typeOf, vv, f.tag})
 		}
 		return props, nil
 	}
 
-	props = append(props, Property{[]string{f.name}, f.typeOf, it})
+	props = append(props, Property{[]string{f.name}, f.typeOf, it, f.tag})
 	return props, nil
 }
 
@@ -80,6 +80,9 @@ func SaveStruct(src interface{}) (map[string]Property, error) {
 	if err != nil {
 		return nil, err
 	}
+	if ety.isDynamic {
+		return nil, fmt.Errorf("goloquent: dynamic entity %v is not supported", vi.Type())
+	}
 
 	data := make(map[string]Property)
 	for _, f := range ety.codec.fields {
@@ -166,6 +169,8 @@ func interfaceToValue(it interface{}) (interface{}, error) {
 		value = vi
 	case json.RawMessage:
 		value = vi
+	case codecValue:
+		value = vi.value
 	case []byte:
 		value = base64.StdEncoding.EncodeToString(vi)
 	case *datastore.Key:
@@ -180,11 +185,13 @@ func interfaceToValue(it interface{}) (interface{}, error) {
 		if vv.IsNil() {
 			return nil, nil
 		}
-		value = (*SoftDelete(vi)).UTC().Format("2006-01-02 15:04:05")
+		value = formatTime(*SoftDelete(vi), 0)
 	case Date:
 		value = time.Time(vi).Format("2006-01-02")
+	case Decimal:
+		value = vi.String()
 	case time.Time:
-		value = vi.UTC().Format("2006-01-02 15:04:05")
+		value = vi.UTC().Format(timeLayout)
 	case geoLocation:
 		b, _ := json.Marshal(vi)
 		value = json.RawMessage(b)
@@ -262,6 +269,9 @@ func saveStructField(sc *StructCodec, v reflect.Value) (map[string]interface{},
 func saveField(f field, v reflect.Value) (interface{}, error) {
 	var it interface{}
 	t := v.Type()
+	if c := codecOf(t); c != nil {
+		return c.encode(v)
+	}
 
 	switch vi := v.Interface().(type) {
 	case *datastore.Key, time.Time:
@@ -271,7 +281,7 @@ func saveField(f field, v reflect.Value) (interface{}, error) {
 			return json.RawMessage("null"), nil
 		}
 		it = vi
-	case Date:
+	case Date, Decimal:
 		it = vi
 	case datastore.GeoPoint:
 		it = geoLocation{vi.Lat, vi.Lng}
@@ -35,6 +35,19 @@ func getColumns(prefix []string, codec *StructCodec) []Column {
 	return columns
 }
 
+// parentKeyColumn is the materialized parent key path, it's derive from the primary key
+func parentKeyColumn() Column {
+	t := tag{
+		name:    parentColumn,
+		options: map[string]bool{"index": true},
+		others:  make(map[string]string),
+	}
+	return Column{
+		names: []string{parentColumn},
+		field: newField(t, nil, nil, nil, typeOfPtrKey, true, nil),
+	}
+}
+
 // convertMulti will convert any single model to pointer of []model
 func convertMulti(v reflect.Value) reflect.Value {
 	vi := reflect.MakeSlice(reflect.SliceOf(v.Type()), 1, 1)
@@ -45,13 +58,16 @@ func convertMulti(v reflect.Value) reflect.Value {
 }
 
 type entity struct {
-	name       string
-	typeOf     reflect.Type
-	isMultiPtr bool
-	slice      reflect.Value
-	codec      *StructCodec
-	fields     map[string]Column
-	columns    []Column
+	name         string
+	typeOf       reflect.Type
+	isMultiPtr   bool
+	isDynamic    bool
+	slice        reflect.Value
+	codec        *StructCodec
+	fields       map[string]Column
+	columns      []Column
+	tableColumns []string
+	boolColumns  []string
 }
 
 // TODO: check primary key must present
@@ -65,15 +81,26 @@ func newEntity(it interface{}) (*entity, error) {
 	t := v.Type().Elem()
 	switch t.Kind() {
 	case reflect.Slice, reflect.Array:
+		if isDynamicType(t) {
+			return newDynamicEntity(t, convertMulti(v)), nil
+		}
 		t = t.Elem()
 		if t.Kind() == reflect.Ptr {
 			isMultiPtr = true
 			t = t.Elem()
 		}
+		if isDynamicType(t) {
+			e := newDynamicEntity(t, v)
+			e.isMultiPtr = isMultiPtr
+			return e, nil
+		}
 		if t.Kind() != reflect.Struct {
 			return nil, fmt.Errorf("goloquent: invalid entity data type : %v, it should be struct", t)
 		}
 	case reflect.Struct:
+		if isDynamicType(t) {
+			return newDynamicEntity(t, convertMulti(v)), nil
+		}
 		isMultiPtr = true
 		v = convertMulti(v)
 	default:
@@ -94,6 +121,7 @@ func newEntity(it interface{}) (*entity, error) {
 	if _, hasKey := fields[keyFieldName]; !hasKey {
 		return nil, fmt.Errorf("goloquent: entity %v doesn't has primary key property", t)
 	}
+	cols = append(cols, parentKeyColumn())
 
 	return &entity{
 		name:       t.Name(),
@@ -0,0 +1,172 @@
+package goloquent
+
+import (
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/datastore"
+)
+
+// SetEntityCache : set the storage of entity cache which used by `Find` and `GetMulti`, nil will disable the cache
+func (db *DB) SetEntityCache(c Cache) {
+	db.entityCache = c
+}
+
+// entityVersionKey is the cache key of table version of entity cache,
+// it will change when the table is mutated by query, eg. `Update` or `Flush`
+func (db *DB) entityVersionKey(table string) string {
+	return fmt.Sprintf("goloquent:entity:%s:%s:%s", db.driver, db.name, table)
+}
+
+func (db *DB) entityCacheKey(table string, k *datastore.Key) string {
+	v := tableVersion(db.entityCache, db.entityVersionKey(table))
+	return fmt.Sprintf("%s:%s:%s", db.entityVersionKey(table), v, stringPk(k))
+}
+
+// invalidateEntities will evict all the cached entities of the tables
+func (db *DB) invalidateEntities(tables ...string) {
+	if db.entityCache == nil {
+		return
+	}
+	version := newTableVersion()
+	for _, t := range tables {
+		if t == "" {
+			continue
+		}
+		db.entityCache.Set(db.entityVersionKey(t), version, 0)
+	}
+}
+
+// useEntityCache will check whether the query is only lookup by keys, the cache is bypassed in transaction
+func (b *builder) useEntityCache(t reflect.Type) bool {
+	if b.db.entityCache == nil || b.db.inTransaction() || isDynamicType(t) {
+		return false
+	}
+	query := b.query
+	if len(query.projection) > 0 || len(query.distinctOn) > 0 || len(query.omits) > 0 ||
+		len(query.ancestors) > 0 || len(query.joins) > 0 || query.lockMode > 0 {
+		return false
+	}
+	for _, f := range query.filters {
+		if f.field != keyFieldName {
+			return false
+		}
+	}
+	return true
+}
+
+// loadEntity will return the iterator of cached entity
+func (b *builder) loadEntity(table string, k *datastore.Key, t reflect.Type) *Iterator {
+	v, isOk := b.db.entityCache.Get(b.db.entityCacheKey(table, k))
+	if !isOk {
+		return nil
+	}
+	it := &Iterator{table: table, position: -1, timeConf: b.db.client.timeConfig()}
+	typeName, err := decodeResult(v, it)
+	if err != nil || typeName != t.String() || it.First() == nil {
+		return nil
+	}
+	// soft deleted entity is only visible in unscoped query
+	if it.Get(softDeleteColumn) != nil && !b.query.noScope {
+		return nil
+	}
+	return it
+}
+
+// storeEntity will cache the current record of the iterator
+func (b *builder) storeEntity(table string, k *datastore.Key, t reflect.Type, it *Iterator) {
+	nit := &Iterator{
+		columns: it.columns,
+		types:   it.types,
+		results: []map[string][]byte{it.results[it.position]},
+	}
+	v, err := encodeResult(nit, t.String())
+	if err != nil {
+		return
+	}
+	b.db.entityCache.Set(b.db.entityCacheKey(table, k), v, 0)
+}
+
+// findEntity is `get` by key through the entity cache
+func (b *builder) findEntity(k *datastore.Key, model interface{}) error {
+	e, err := newEntity(model)
+	if err != nil {
+		return err
+	}
+	e.setName(b.query.table)
+	t := reflect.TypeOf(model).Elem()
+	if it := b.loadEntity(e.Name(), k, t); it != nil {
+		return it.Scan(model)
+	}
+
+	cmd, err := b.getCommand(e)
+	if err != nil {
+		return err
+	}
+	it, err := b.run(e.Name(), cmd)
+	if err != nil {
+		return err
+	}
+	if it.First() == nil {
+		return ErrNoSuchEntity
+	}
+	b.storeEntity(e.Name(), k, t, it)
+	return it.Scan(model)
+}
+
+// cacheEntities will write the saved entities to the cache, the entities will be evicted instead
+// if it's not same as the record in database, eg. some columns are omitted
+func (b *builder) cacheEntities(e *entity, evict bool) {
+	if b.db.entityCache == nil || e.isDynamic {
+		return
+	}
+	// the mutation is not visible to others until commit
+	if b.db.inTransaction() {
+		b.db.invalidateEntities(e.Name())
+		return
+	}
+
+	v := e.slice.Elem()
+	cols := e.Columns()
+next:
+	for i := 0; i < v.Len(); i++ {
+		f := reflect.Indirect(v.Index(i))
+		vi := reflect.New(f.Type())
+		vi.Elem().Set(f)
+		props, err := SaveStruct(vi.Interface())
+		if err != nil {
+			b.db.invalidateEntities(e.Name())
+			return
+		}
+		k, isOk := props[keyFieldName].Value.(*datastore.Key)
+		if !isOk || k == nil || k.Incomplete() {
+			continue
+		}
+		if evict || len(b.query.omits) > 0 {
+			b.db.entityCache.Delete(b.db.entityCacheKey(e.Name(), k))
+			continue
+		}
+
+		it := &Iterator{table: e.Name(), position: 0, columns: cols}
+		for _, c := range cols {
+			var vv interface{}
+			switch c {
+			case pkColumn:
+				vv = stringPk(k)
+			case parentColumn:
+				if k.Parent != nil {
+					vv = stringifyKey(k.Parent)
+				}
+			default:
+				// same as the value written to database, eg. the precision of time and the native array
+				vv, err = b.propertyValue(props[c])
+				if err != nil {
+					b.db.entityCache.Delete(b.db.entityCacheKey(e.Name(), k))
+					continue next
+				}
+			}
+			it.put(0, c, vv)
+		}
+		b.storeEntity(e.Name(), k, f.Type(), it)
+	}
+}
@@ -0,0 +1,77 @@
+package goloquent
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+func TestEntityCache(t *testing.T) {
+	type Merchant struct {
+		Key    *datastore.Key `goloquent:"__key__"`
+		Name   string
+		Active bool
+		Tags   []string
+	}
+
+	db := newFakeDB(t)
+	db.SetEntityCache(NewLRUCache(10))
+
+	m := &Merchant{Key: datastore.NameKey("Merchant", "m1", nil), Name: "Goloquent", Active: true, Tags: []string{"a"}}
+	if err := db.Create(m); err != nil {
+		t.Fatal(err)
+	}
+
+	before := atomic.LoadInt64(&fakeQueried)
+	result := new(Merchant)
+	if err := db.Find(m.Key, result); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt64(&fakeQueried) != before {
+		t.Fatal("entity should be loaded from cache")
+	}
+	if !result.Key.Equal(m.Key) || result.Name != m.Name || !result.Active || len(result.Tags) != 1 {
+		t.Fatalf(errUnexpectedResult, "Find")
+	}
+
+	results := make([]*Merchant, 0)
+	if err := db.GetMulti([]*datastore.Key{m.Key}, &results); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt64(&fakeQueried) != before || len(results) != 1 || results[0].Name != m.Name {
+		t.Fatal("entities should be loaded from cache")
+	}
+
+	db.invalidateEntities("Merchant")
+	if err := db.Find(m.Key, result); err != ErrNoSuchEntity {
+		t.Fatal("entity cache should be invalidated")
+	}
+	if atomic.LoadInt64(&fakeQueried) == before {
+		t.Fatal("entity should be loaded from database")
+	}
+}
+
+func TestEntityCacheVersionEvicted(t *testing.T) {
+	type Merchant struct {
+		Key  *datastore.Key `goloquent:"__key__"`
+		Name string
+	}
+
+	db := newFakeDB(t)
+	cache := NewLRUCache(10)
+	db.SetEntityCache(cache)
+
+	m := &Merchant{Key: datastore.NameKey("Merchant", "m1", nil), Name: "Goloquent"}
+	if err := db.Create(m); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table("Merchant").Where("Name", "=", "Goloquent").Update(map[string]interface{}{"Name": "New"}); err != nil {
+		t.Fatal(err)
+	}
+	// the version key of table is evicted from the cache
+	cache.Delete(db.entityVersionKey("Merchant"))
+	if err := db.Find(m.Key, new(Merchant)); err != ErrNoSuchEntity {
+		t.Fatal("entity cached before the update shouldn't be returned after the version is evicted")
+	}
+}
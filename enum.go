@@ -0,0 +1,73 @@
+package goloquent
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Enumerator : the string type which only accept the fixed set of values, eg.
+// `func (Status) Enum() []string { return []string{"active", "suspended"} }`
+type Enumerator interface {
+	Enum() []string
+}
+
+var typeOfEnumerator = reflect.TypeOf((*Enumerator)(nil)).Elem()
+
+// enumValues will return the members of enum, the tag `enum=A|B|C` is preferred over `Enumerator`,
+// it's only applicable for string field
+func enumValues(t reflect.Type, tg tag) []string {
+	if t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.String {
+		return nil
+	}
+	if v := tg.Get("enum"); v != "" {
+		return strings.Split(v, "|")
+	}
+	if t.Implements(typeOfEnumerator) {
+		return reflect.Zero(t).Interface().(Enumerator).Enum()
+	}
+	if reflect.PtrTo(t).Implements(typeOfEnumerator) {
+		return reflect.New(t).Interface().(Enumerator).Enum()
+	}
+	return nil
+}
+
+// enumList will return the quoted members of enum, eg. `'A','B'`
+func enumList(values []string) string {
+	list := make([]string, len(values))
+	for i, v := range values {
+		list[i] = "'" + escapeSingleQuote(v) + "'"
+	}
+	return strings.Join(list, ",")
+}
+
+// validateEnum will check whether the value is one of the enum members, nil pointer is allowed
+func validateEnum(p Property) error {
+	values := enumValues(p.typeOf, p.tag)
+	if len(values) <= 0 {
+		return nil
+	}
+	vi, err := interfaceToValue(p.Value)
+	if err != nil {
+		return err
+	}
+	if vi == nil {
+		return nil
+	}
+	str, isOk := vi.(string)
+	if isOk {
+		for _, v := range values {
+			if v == str {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("goloquent: invalid enum value %v of %q, it must be one of %s",
+		vi, p.Name(), strings.Join(values, "|"))
+}
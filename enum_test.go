@@ -0,0 +1,141 @@
+package goloquent
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+type testStatus string
+
+func (testStatus) Enum() []string {
+	return []string{"active", "suspended"}
+}
+
+type testRole string
+
+func (*testRole) Enum() []string {
+	return []string{"admin", "member"}
+}
+
+type testAccount struct {
+	Key    *datastore.Key `goloquent:"__key__"`
+	Status testStatus
+	Tier   *string `goloquent:",enum=Basic|Pro|It's"`
+	Name   string
+	Role   testRole
+}
+
+func TestEnum(t *testing.T) {
+	codec, err := getStructCodec(&testAccount{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][2]string{
+		"Status": {
+			"enum('active','suspended') CHARACTER SET `utf8mb4` COLLATE `utf8mb4_unicode_ci` NOT NULL DEFAULT \"active\"",
+			`varchar(191) CONSTRAINT "Status_enum" CHECK ("Status" IN ('active','suspended')) NOT NULL DEFAULT 'active'`,
+		},
+		"Tier": {
+			"enum('Basic','Pro','It''s') CHARACTER SET `utf8mb4` COLLATE `utf8mb4_unicode_ci`",
+			`varchar(191) CONSTRAINT "Tier_enum" CHECK ("Tier" IN ('Basic','Pro','It''s'))`,
+		},
+		"Role": {
+			"enum('admin','member') CHARACTER SET `utf8mb4` COLLATE `utf8mb4_unicode_ci` NOT NULL DEFAULT \"admin\"",
+			`varchar(191) CONSTRAINT "Role_enum" CHECK ("Role" IN ('admin','member')) NOT NULL DEFAULT 'admin'`,
+		},
+		"Name": {
+			"varchar(191) CHARACTER SET `utf8mb4` COLLATE `utf8mb4_unicode_ci` NOT NULL DEFAULT \"\"",
+			"varchar(191) NOT NULL DEFAULT ''",
+		},
+	}
+	for _, c := range getColumns(nil, codec) {
+		e, isOk := expected[c.Name()]
+		if !isOk {
+			continue
+		}
+		if dt := new(mysql).DataType(new(mysql).GetSchema(c)[0]); dt != e[0] {
+			t.Fatalf("unexpected mysql schema of %q, %s", c.Name(), dt)
+		}
+		p := new(postgres)
+		if dt := p.DataType(p.GetSchema(c)[0]); dt != e[1] {
+			t.Fatalf("unexpected postgres schema of %q, %s", c.Name(), dt)
+		}
+	}
+
+	db := newFakeDB(t)
+	b := newBuilder(db.NewQuery())
+	tier := "Pro"
+	for _, a := range []testAccount{
+		{Status: "active", Tier: &tier, Role: "admin"},
+		{Status: "suspended", Role: "member"},
+	} {
+		props, err := SaveStruct(&a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range props {
+			if _, err := b.propertyValue(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	invalid := "Premium"
+	for _, a := range []testAccount{
+		{Status: "deleted"},
+		{Status: ""},
+		{Status: "active", Tier: &invalid},
+		{Status: "active", Role: "owner"},
+	} {
+		props, err := SaveStruct(&a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err1 := b.propertyValue(props["Status"])
+		_, err2 := b.propertyValue(props["Tier"])
+		_, err3 := b.propertyValue(props["Role"])
+		if err1 == nil && err2 == nil && err3 == nil {
+			t.Fatalf("invalid enum value of %v should return error", a)
+		}
+	}
+
+	if _, err := b.updateWithMap(reflect.ValueOf(map[string]interface{}{"Status": testStatus("deleted")})); err == nil ||
+		!strings.Contains(err.Error(), "invalid enum value") {
+		t.Fatal("invalid enum value of map update should return error")
+	}
+	if _, err := b.updateWithMap(reflect.ValueOf(map[string]interface{}{"Status": testStatus("active")})); err != nil {
+		t.Fatal(err)
+	}
+
+	// the `enum=` tag is unknown for map update, the plain string is left to the column of the table
+	cmd, err := b.updateWithMap(reflect.ValueOf(map[string]interface{}{"Tier": "bogus"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cmd.arguments, []interface{}{"bogus"}) {
+		t.Fatalf(errUnexpectedResult, "updateWithMap")
+	}
+}
+
+func TestAlterEnum(t *testing.T) {
+	p := new(postgres)
+	sc := Schema{Name: "Tier", DataType: "varchar(191)", Enum: []string{"Basic", "Pro"}}
+	if str := p.alterEnum(sc, true); str != `DROP CONSTRAINT IF EXISTS "Tier_enum",ADD CONSTRAINT "Tier_enum" CHECK ("Tier" IN ('Basic','Pro')),` {
+		t.Fatalf("unexpected alter enum of existing column, %s", str)
+	}
+	if str := p.alterEnum(sc, false); str != `ADD CONSTRAINT "Tier_enum" CHECK ("Tier" IN ('Basic','Pro')),` {
+		t.Fatalf("unexpected alter enum of new column, %s", str)
+	}
+
+	// the enum option is removed from the model
+	sc.Enum = nil
+	if str := p.alterEnum(sc, true); str != `DROP CONSTRAINT IF EXISTS "Tier_enum",` {
+		t.Fatalf("unexpected alter enum of removed enum, %s", str)
+	}
+	if str := p.alterEnum(sc, false); str != "" {
+		t.Fatalf("unexpected alter enum of new column, %s", str)
+	}
+}
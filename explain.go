@@ -0,0 +1,257 @@
+package goloquent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ToSQL : return the statement of the operation without executing it, the arguments are same as the operation,
+// eg. `ToSQL(OpGet, &[]User{})`, `ToSQL(OpUpdate, map[string]interface{}{"Name": "x"})`
+func (q *Query) ToSQL(op Operation, args ...interface{}) (*Stmt, error) {
+	if err := q.getError(); err != nil {
+		return nil, err
+	}
+	q = q.clone()
+	if op != OpGet {
+		if err := q.checkJoin(string(op)); err != nil {
+			return nil, err
+		}
+	}
+	var (
+		cmd *stmt
+		err error
+	)
+	switch op {
+	case OpGet:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("goloquent: %s statement requires the model", op)
+		}
+		cmd, err = newBuilder(q).selectStmt(args[0])
+	case OpPaginate:
+		if len(args) != 2 {
+			return nil, fmt.Errorf("goloquent: %s statement requires the pagination and the model", op)
+		}
+		p, isOk := args[0].(*Pagination)
+		if !isOk || p == nil {
+			return nil, fmt.Errorf("goloquent: invalid pagination %T", args[0])
+		}
+		// the cursor is resolved by query, so the statement is only available for the first page
+		if p.Cursor != "" {
+			return nil, fmt.Errorf("goloquent: %s statement with cursor is unsupported", op)
+		}
+		pp := *p
+		q, err = q.paginateQuery(&pp)
+		if err != nil {
+			return nil, err
+		}
+		cmd, err = newBuilder(q).selectStmt(args[1])
+	case OpUpdate:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("goloquent: %s statement requires the value", op)
+		}
+		cmd, _, err = newBuilder(q).updateStmt(args[0])
+		if err == nil && cmd == nil {
+			err = fmt.Errorf("goloquent: nothing to update")
+		}
+	case OpFlush:
+		if q.table == "" {
+			return nil, fmt.Errorf("goloquent: unable to perform delete without table name")
+		}
+		cmd, err = newBuilder(q).deleteByQueryStmt()
+	default:
+		return nil, fmt.Errorf("goloquent: unsupported operation %q", op)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cmd.crud, cmd.table = op, q.table
+	return &Stmt{stmt: *cmd, replacer: q.db.dialect}, nil
+}
+
+// selectStmt will return the select statement of the model, same as `Get`
+func (b *builder) selectStmt(model interface{}) (*stmt, error) {
+	if len(b.query.joins) > 0 {
+		t, _, _, err := joinModel(model)
+		if err != nil {
+			return nil, err
+		}
+		cmd, _, err := b.joinStmt(t)
+		return cmd, err
+	}
+	if reflect.TypeOf(model) == nil || reflect.TypeOf(model).Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("goloquent: model is not addressable")
+	}
+	e, err := newEntity(model)
+	if err != nil {
+		return nil, err
+	}
+	e.setName(b.query.table)
+	return b.getCommand(e)
+}
+
+// PlanStep : the table access of the execution plan
+type PlanStep struct {
+	Table string
+	// Type is the access type, eg. `ALL`, `ref` in mysql or `Seq Scan`, `Index Scan` in postgres
+	Type  string
+	Index string
+	Rows  float64
+	// FullScan is true when every record of the table is read
+	FullScan bool
+}
+
+// Plan : the execution plan of the query
+type Plan struct {
+	Raw   string
+	Steps []PlanStep
+}
+
+// HasFullTableScan : return true if any table is full scanned
+func (p *Plan) HasFullTableScan() bool {
+	for _, s := range p.Steps {
+		if s.FullScan {
+			return true
+		}
+	}
+	return false
+}
+
+// FullScans : return the tables which are full scanned
+func (p *Plan) FullScans() []string {
+	tables := make([]string, 0)
+	for _, s := range p.Steps {
+		if s.FullScan {
+			tables = append(tables, s.Table)
+		}
+	}
+	return tables
+}
+
+// Explain : run the query with `EXPLAIN` and return the execution plan
+func (q *Query) Explain() (*Plan, error) {
+	if err := q.getError(); err != nil {
+		return nil, err
+	}
+	q = q.clone()
+	cmd, err := newBuilder(q).explainStmt()
+	if err != nil {
+		return nil, err
+	}
+	d, isOk := q.db.dialect.(ExplainDialect)
+	if !isOk {
+		return nil, errUnsupported(q.db.dialect, "explain")
+	}
+	return d.Explain(&Stmt{stmt: *cmd, replacer: q.db.dialect})
+}
+
+// explainStmt will return the select statement of the query without model
+func (b *builder) explainStmt() (*stmt, error) {
+	query := b.query
+	if query.table == "" {
+		return nil, fmt.Errorf("goloquent: unable to explain without table name")
+	}
+	if !query.noScope && b.hasSoftDelete(query.table) {
+		query.filters = append(query.filters, Filter{
+			field:    b.baseColumn(query, softDeleteColumn),
+			operator: Equal,
+			value:    nil,
+		})
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteString(b.buildSelect(query).string())
+	from, err := b.buildFrom(query)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(from.string())
+	cmd, err := b.buildStmt(query)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(cmd.string() + ";")
+	return &stmt{statement: buf, arguments: cmd.arguments}, nil
+}
+
+// parseMySQLPlan will parse the result of `EXPLAIN FORMAT=JSON`
+func parseMySQLPlan(b []byte) (*Plan, error) {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("goloquent: invalid execution plan : %v", err)
+	}
+	p := &Plan{Raw: string(b)}
+	var walk func(interface{})
+	walk = func(v interface{}) {
+		switch vi := v.(type) {
+		case []interface{}:
+			for _, x := range vi {
+				walk(x)
+			}
+		case map[string]interface{}:
+			if t, isOk := vi["table"].(map[string]interface{}); isOk {
+				s := PlanStep{}
+				s.Table, _ = t["table_name"].(string)
+				s.Type, _ = t["access_type"].(string)
+				s.Index, _ = t["key"].(string)
+				s.Rows, _ = t["rows_examined_per_scan"].(float64)
+				s.FullScan = strings.EqualFold(s.Type, "ALL")
+				p.Steps = append(p.Steps, s)
+			}
+			// the subquery may nested in the table, eg. `materialized_from_subquery`
+			keys := make([]string, 0, len(vi))
+			for k := range vi {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(vi[k])
+			}
+		}
+	}
+	walk(v)
+	return p, nil
+}
+
+// parsePostgresPlan will parse the result of `EXPLAIN (FORMAT JSON)`
+func parsePostgresPlan(b []byte) (*Plan, error) {
+	var v []struct {
+		Plan json.RawMessage `json:"Plan"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("goloquent: invalid execution plan : %v", err)
+	}
+	type node struct {
+		NodeType     string  `json:"Node Type"`
+		RelationName string  `json:"Relation Name"`
+		IndexName    string  `json:"Index Name"`
+		PlanRows     float64 `json:"Plan Rows"`
+		Plans        []node  `json:"Plans"`
+	}
+	p := &Plan{Raw: string(b)}
+	var walk func(n node)
+	walk = func(n node) {
+		if n.RelationName != "" {
+			p.Steps = append(p.Steps, PlanStep{
+				Table:    n.RelationName,
+				Type:     n.NodeType,
+				Index:    n.IndexName,
+				Rows:     n.PlanRows,
+				FullScan: n.NodeType == "Seq Scan",
+			})
+		}
+		for _, c := range n.Plans {
+			walk(c)
+		}
+	}
+	for _, x := range v {
+		n := node{}
+		if err := json.Unmarshal(x.Plan, &n); err != nil {
+			return nil, fmt.Errorf("goloquent: invalid execution plan : %v", err)
+		}
+		walk(n)
+	}
+	return p, nil
+}
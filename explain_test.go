@@ -0,0 +1,109 @@
+package goloquent
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+func TestToSQL(t *testing.T) {
+	type User struct {
+		Key  *datastore.Key `goloquent:"__key__"`
+		Name string
+		Age  int
+	}
+	db := newFakeDB(t)
+	q := db.Table("User").WhereEqual("Name", "Joe")
+
+	s, err := q.ToSQL(OpGet, &[]User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(s.Raw(), "SELECT ") || !strings.Contains(s.Raw(), "WHERE `Name` = ?") ||
+		!reflect.DeepEqual(s.Arguments(), []interface{}{"Joe"}) {
+		t.Fatalf(errUnexpectedResult, "ToSQL(OpGet)")
+	}
+
+	s, err = q.ToSQL(OpPaginate, &Pagination{Limit: 10}, &[]User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(s.Raw(), "ORDER BY `$Key` LIMIT 11") {
+		t.Fatalf(errUnexpectedResult, "ToSQL(OpPaginate)")
+	}
+	if _, err := q.ToSQL(OpPaginate, &Pagination{Cursor: "abc"}, &[]User{}); err == nil {
+		t.Fatal(`"ToSQL" should return error on pagination with cursor`)
+	}
+
+	s, err = q.ToSQL(OpUpdate, map[string]interface{}{"Age": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.String() != "UPDATE ``.`User` SET `Age` = 10 WHERE `Name` = \"Joe\";" {
+		t.Fatalf(errUnexpectedResult, "ToSQL(OpUpdate)")
+	}
+
+	s, err = q.ToSQL(OpFlush)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.String() != "DELETE FROM ``.`User` WHERE `Name` = \"Joe\";" {
+		t.Fatalf(errUnexpectedResult, "ToSQL(OpFlush)")
+	}
+
+	if _, err := q.ToSQL(Operation("truncate")); err == nil {
+		t.Fatal(`"ToSQL" should return error on unsupported operation`)
+	}
+}
+
+func TestParseMySQLPlan(t *testing.T) {
+	p, err := parseMySQLPlan([]byte(`{
+  "query_block": {
+    "select_id": 1,
+    "nested_loop": [
+      {"table": {"table_name": "u", "access_type": "ALL", "rows_examined_per_scan": 1000}},
+      {"table": {"table_name": "m", "access_type": "eq_ref", "key": "PRIMARY", "rows_examined_per_scan": 1}}
+    ]
+  }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Steps) != 2 || !p.HasFullTableScan() ||
+		!reflect.DeepEqual(p.FullScans(), []string{"u"}) ||
+		p.Steps[1].Index != "PRIMARY" || p.Steps[0].Rows != 1000 {
+		t.Fatalf(errUnexpectedResult, "parseMySQLPlan")
+	}
+
+	p, err = parseMySQLPlan([]byte(`{"query_block": {"table": {"table_name": "User", "access_type": "ref", "key": "idx_name"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.HasFullTableScan() || len(p.Steps) != 1 {
+		t.Fatalf(errUnexpectedResult, "parseMySQLPlan")
+	}
+}
+
+func TestParsePostgresPlan(t *testing.T) {
+	p, err := parsePostgresPlan([]byte(`[{"Plan": {
+  "Node Type": "Hash Join", "Plan Rows": 10,
+  "Plans": [
+    {"Node Type": "Seq Scan", "Relation Name": "User", "Plan Rows": 1000},
+    {"Node Type": "Hash", "Plans": [
+      {"Node Type": "Index Scan", "Relation Name": "Merchant", "Index Name": "Merchant_pkey", "Plan Rows": 1}
+    ]}
+  ]
+}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Steps) != 2 || !reflect.DeepEqual(p.FullScans(), []string{"User"}) ||
+		p.Steps[1].Index != "Merchant_pkey" {
+		t.Fatalf(errUnexpectedResult, "parsePostgresPlan")
+	}
+	if _, err := parsePostgresPlan([]byte(`{}`)); err == nil {
+		t.Fatal(`"parsePostgresPlan" should return error on invalid plan`)
+	}
+}
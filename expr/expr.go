@@ -36,3 +36,21 @@ func Field(name string, vals interface{}) (f F) {
 	}
 	return
 }
+
+// Distance : sort by the distance between the geo point field and the point
+type Distance struct {
+	Name      string
+	Lat       float64
+	Lng       float64
+	Direction Direction
+}
+
+// Relevance : sort by the full text search relevance of the fields
+type Relevance struct {
+	Fields   []string
+	Query    string
+	Language string
+	// Boolean is ranking the query in boolean mode, it's inherited from the `Match` filter of the same query
+	Boolean   bool
+	Direction Direction
+}
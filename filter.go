@@ -14,7 +14,9 @@ type Filter struct {
 	operator operator
 	value    interface{}
 	isJSON   bool
-	raw      string
+	isNative bool
+	// isIndexed is whether the json path is indexed with `index=path` option of the model
+	isIndexed bool
 }
 
 // Field :
@@ -27,6 +29,11 @@ func (f Filter) IsJSON() bool {
 	return f.isJSON
 }
 
+// IsNativeArray : the field is migrated as native array with `array` option, otherwise the slice is stored as json
+func (f Filter) IsNativeArray() bool {
+	return f.isNative
+}
+
 // JSON :
 type JSON struct {
 }
@@ -55,6 +62,9 @@ func normalizeValue(val interface{}) (interface{}, error) {
 	v := reflect.ValueOf(val)
 	var it interface{}
 	t := v.Type()
+	if c := codecOf(t); c != nil {
+		return c.encode(v)
+	}
 	switch vi := v.Interface().(type) {
 	case *datastore.Key:
 		if vi == nil {
@@ -65,7 +75,7 @@ func normalizeValue(val interface{}) (interface{}, error) {
 		it = geoLocation{vi.Lat, vi.Lng}
 	case time.Time:
 		it = vi
-	case Date:
+	case Date, Decimal:
 		it = vi
 	default:
 		switch t.Kind() {
@@ -0,0 +1,48 @@
+package goloquent
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SearchMode : is the mode of full text search
+type SearchMode int
+
+// full text search modes :
+const (
+	NaturalLanguageMode SearchMode = iota
+	BooleanMode
+)
+
+// defaultTextSearchConfig is the text search configuration of postgres when language is not specified,
+// the language of query must be same as the index, otherwise the index is not applicable
+const defaultTextSearchConfig = "simple"
+
+var textSearchConfigRgx = regexp.MustCompile(`^[a-zA-Z_]+$`)
+
+type fullTextSearch struct {
+	fields   []string
+	query    string
+	mode     SearchMode
+	language string
+	// perField is matching every field individually, it's only applicable when every field has its own full text index
+	perField bool
+}
+
+// textSearchConfig will validate the language, because it's not able to bind as variable
+func textSearchConfig(lang ...string) (string, error) {
+	cfg := defaultTextSearchConfig
+	if len(lang) > 0 && strings.TrimSpace(lang[0]) != "" {
+		cfg = strings.ToLower(strings.TrimSpace(lang[0]))
+	}
+	if !textSearchConfigRgx.MatchString(cfg) {
+		return "", fmt.Errorf("goloquent: invalid full text search language %q", cfg)
+	}
+	return cfg, nil
+}
+
+// fullTextIndexName :
+func fullTextIndexName(table, field string) string {
+	return fmt.Sprintf("%s_%s_fulltext", table, field)
+}
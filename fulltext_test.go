@@ -0,0 +1,118 @@
+package goloquent
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/si3nloong/goloquent/expr"
+)
+
+func TestTextSearchConfig(t *testing.T) {
+	if cfg, err := textSearchConfig(); err != nil || cfg != defaultTextSearchConfig {
+		t.Fatalf(errUnexpectedResult, "textSearchConfig")
+	}
+	if cfg, err := textSearchConfig(" English "); err != nil || cfg != "english" {
+		t.Fatalf(errUnexpectedResult, "textSearchConfig")
+	}
+	if _, err := textSearchConfig("english'; DROP TABLE"); err == nil {
+		t.Fatal(`"textSearchConfig" should return error on invalid language`)
+	}
+}
+
+func TestFilterFullText(t *testing.T) {
+	f := Filter{
+		operator: MatchAgainst,
+		value:    fullTextSearch{[]string{"Title", "Body"}, "hello world", BooleanMode, "english", true},
+	}
+
+	str, args, err := new(sequel).FilterFullText(f)
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if str != "(MATCH(`Title`) AGAINST(?? IN BOOLEAN MODE) OR MATCH(`Body`) AGAINST(?? IN BOOLEAN MODE))" ||
+		!reflect.DeepEqual(args, []interface{}{"hello world", "hello world"}) {
+		t.Fatalf(errUnexpectedResult, "FilterFullText")
+	}
+
+	f.value = fullTextSearch{[]string{"Title", "Body"}, "hello world", NaturalLanguageMode, "", false}
+	str, args, err = new(sequel).FilterFullText(f)
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if str != "MATCH(`Title`,`Body`) AGAINST(?? IN NATURAL LANGUAGE MODE)" ||
+		!reflect.DeepEqual(args, []interface{}{"hello world"}) {
+		t.Fatalf(errUnexpectedResult, "FilterFullText")
+	}
+
+	f.value = fullTextSearch{[]string{"Title", "Body"}, "hello world", BooleanMode, "english", true}
+	str, _, err = new(postgres).FilterFullText(f)
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if str != `(to_tsvector('english'::regconfig, "Title") @@ websearch_to_tsquery('english'::regconfig, ??) OR `+
+		`to_tsvector('english'::regconfig, "Body") @@ websearch_to_tsquery('english'::regconfig, ??))` {
+		t.Fatalf(errUnexpectedResult, "FilterFullText")
+	}
+
+	str, args, err = new(postgres).FullTextRelevance([]string{"Title"}, "hello", "", NaturalLanguageMode, true)
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if str != `(ts_rank(to_tsvector('simple'::regconfig, "Title"), plainto_tsquery('simple'::regconfig, ??)))` ||
+		!reflect.DeepEqual(args, []interface{}{"hello"}) {
+		t.Fatalf(errUnexpectedResult, "FullTextRelevance")
+	}
+
+	// the relevance is ranked with the same query function as the boolean mode filter
+	str, _, err = new(postgres).FullTextRelevance([]string{"Title"}, "hello -world", "", BooleanMode, true)
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if str != `(ts_rank(to_tsvector('simple'::regconfig, "Title"), websearch_to_tsquery('simple'::regconfig, ??)))` {
+		t.Fatalf(errUnexpectedResult, "FullTextRelevance")
+	}
+	str, _, err = new(sequel).FullTextRelevance([]string{"Title", "Body"}, "+hello -world", "", BooleanMode, false)
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if str != "MATCH(`Title`,`Body`) AGAINST(?? IN BOOLEAN MODE)" {
+		t.Fatalf(errUnexpectedResult, "FullTextRelevance")
+	}
+}
+
+type testArticle struct {
+	Key     *datastore.Key `goloquent:"__key__"`
+	Title   string         `goloquent:",fulltext"`
+	Body    string         `goloquent:",longtext,fulltext"`
+	Summary string
+}
+
+func TestMatch(t *testing.T) {
+	db := newFakeDB(t)
+	checks := map[string]*Query{
+		"(MATCH(`Title`) AGAINST(? IN NATURAL LANGUAGE MODE) OR MATCH(`Body`) AGAINST(? IN NATURAL LANGUAGE MODE))": db.Match([]string{"Title", "Body"}, "hello", NaturalLanguageMode),
+		"MATCH(`Title`,`Summary`) AGAINST(? IN NATURAL LANGUAGE MODE)":                                              db.Match([]string{"Title", "Summary"}, "hello", NaturalLanguageMode),
+		"MATCH(`Title`,`Body`) AGAINST(? IN NATURAL LANGUAGE MODE)":                                                 db.MatchAgainst([]string{"Title", "Body"}, "hello"),
+		"ORDER BY (MATCH(`Title`) AGAINST(? IN NATURAL LANGUAGE MODE) + MATCH(`Body`) AGAINST(? IN NATURAL LANGUAGE MODE)) DESC": db.Match([]string{"Title", "Body"}, "hello", NaturalLanguageMode).
+			OrderBy(expr.Relevance{Fields: []string{"Title", "Body"}, Query: "hello", Direction: expr.Descending}),
+		// the relevance is using the same column list as the filter when the field has no full text index of its own
+		"ORDER BY MATCH(`Title`,`Summary`) AGAINST(? IN NATURAL LANGUAGE MODE) DESC": db.Match([]string{"Title", "Summary"}, "hello", NaturalLanguageMode).
+			OrderBy(expr.Relevance{Fields: []string{"Title", "Summary"}, Query: "hello", Direction: expr.Descending}),
+		// the relevance inherits the boolean mode of the filter
+		"ORDER BY (MATCH(`Title`) AGAINST(? IN BOOLEAN MODE) + MATCH(`Body`) AGAINST(? IN BOOLEAN MODE)) DESC": db.Match([]string{"Title", "Body"}, "+hello -world", BooleanMode).
+			OrderBy(expr.Relevance{Fields: []string{"Title", "Body"}, Query: "+hello -world", Direction: expr.Descending}),
+		"ORDER BY MATCH(`Title`,`Summary`) AGAINST(? IN BOOLEAN MODE)": db.NewQuery().
+			OrderBy(expr.Relevance{Fields: []string{"Title", "Summary"}, Query: "+hello", Boolean: true}),
+	}
+	for raw, q := range checks {
+		s, err := q.ToSQL(OpGet, &[]testArticle{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(s.Raw(), raw) {
+			t.Fatalf("unexpected statement %s", s.Raw())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package goloquent
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/datastore"
+)
+
+// spatialVersion is the mysql version which support the SRID of spatial column
+const spatialVersion = "8.0"
+
+var versionRgx = regexp.MustCompile(`^\d+\.\d+`)
+
+const (
+	geoSRID      = 4326
+	earthRadius  = 6371008.8 // mean earth radius in meters
+	metersPerLat = 111320.0
+)
+
+type geoNear struct {
+	point    datastore.GeoPoint
+	distance float64
+}
+
+type geoBox struct {
+	sw datastore.GeoPoint
+	ne datastore.GeoPoint
+}
+
+// boundingBox will return the south west and north east point which cover the radius
+func boundingBox(g datastore.GeoPoint, meters float64) (datastore.GeoPoint, datastore.GeoPoint) {
+	dLat := meters / metersPerLat
+	dLng := 180.0
+	if cos := math.Cos(g.Lat * math.Pi / 180); cos > 0 {
+		dLng = math.Min(meters/(metersPerLat*cos), 180)
+	}
+	sw := datastore.GeoPoint{Lat: math.Max(g.Lat-dLat, -90), Lng: math.Max(g.Lng-dLng, -180)}
+	ne := datastore.GeoPoint{Lat: math.Min(g.Lat+dLat, 90), Lng: math.Min(g.Lng+dLng, 180)}
+	return sw, ne
+}
+
+// geoToWKB will convert the geo point to mysql internal geometry format,
+// which is 4 bytes SRID follow by WKB in longitude-latitude order
+func geoToWKB(g datastore.GeoPoint) []byte {
+	b := make([]byte, 25)
+	binary.LittleEndian.PutUint32(b[0:], geoSRID)
+	b[4] = 1 // little endian
+	binary.LittleEndian.PutUint32(b[5:], 1)
+	binary.LittleEndian.PutUint64(b[9:], math.Float64bits(g.Lng))
+	binary.LittleEndian.PutUint64(b[17:], math.Float64bits(g.Lat))
+	return b
+}
+
+func wkbToGeo(b []byte) (datastore.GeoPoint, error) {
+	if len(b) != 25 {
+		return datastore.GeoPoint{}, fmt.Errorf("goloquent: invalid geometry value length %d", len(b))
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if b[4] == 0 {
+		order = binary.BigEndian
+	}
+	if order.Uint32(b[5:]) != 1 {
+		return datastore.GeoPoint{}, fmt.Errorf("goloquent: geometry value is not a point")
+	}
+	return datastore.GeoPoint{
+		Lng: math.Float64frombits(order.Uint64(b[9:])),
+		Lat: math.Float64frombits(order.Uint64(b[17:])),
+	}, nil
+}
+
+// parseGeoPoint will accept json, postgres point and mysql geometry format
+func parseGeoPoint(b []byte) (datastore.GeoPoint, error) {
+	str := strings.TrimSpace(b2s(b))
+	switch {
+	case strings.HasPrefix(str, "(") && strings.HasSuffix(str, ")"):
+		paths := strings.Split(strings.Trim(str, "()"), ",")
+		if len(paths) == 2 {
+			lng, err1 := strconv.ParseFloat(strings.TrimSpace(paths[0]), 64)
+			lat, err2 := strconv.ParseFloat(strings.TrimSpace(paths[1]), 64)
+			if err1 == nil && err2 == nil {
+				return datastore.GeoPoint{Lat: lat, Lng: lng}, nil
+			}
+		}
+	case len(b) == 25 && !bytes.HasPrefix(b, []byte("{")):
+		return wkbToGeo(b)
+	default:
+		var g geoLocation
+		if err := json.Unmarshal(bytes.Trim(b, `"`), &g); err == nil {
+			return datastore.GeoPoint{Lat: g.Latitude, Lng: g.Longitude}, nil
+		}
+	}
+	return datastore.GeoPoint{}, fmt.Errorf("goloquent: corrupted geolocation value, %s", str)
+}
+
+// geoPointOf will return the geo point if the value is a geo point
+func geoPointOf(it interface{}) (datastore.GeoPoint, bool) {
+	switch vi := it.(type) {
+	case datastore.GeoPoint:
+		return vi, true
+	case *datastore.GeoPoint:
+		if vi != nil {
+			return *vi, true
+		}
+	case geoLocation:
+		return datastore.GeoPoint{Lat: vi.Latitude, Lng: vi.Longitude}, true
+	}
+	return datastore.GeoPoint{}, false
+}
@@ -0,0 +1,129 @@
+package goloquent
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+func TestGeoWKB(t *testing.T) {
+	g := datastore.GeoPoint{Lat: 3.139003, Lng: 101.686855}
+	b := geoToWKB(g)
+	if len(b) != 25 {
+		t.Fatalf(errUnexpectedResult, "geoToWKB")
+	}
+	result, err := wkbToGeo(b)
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if result != g {
+		t.Fatalf(errUnexpectedResult, "wkbToGeo")
+	}
+	if _, err := wkbToGeo(b[:10]); err == nil {
+		t.Fatal(`"wkbToGeo" should return error on invalid length`)
+	}
+}
+
+func TestParseGeoPoint(t *testing.T) {
+	g := datastore.GeoPoint{Lat: 3.1, Lng: 101.6}
+	checks := [][]byte{
+		[]byte("(101.6,3.1)"),
+		[]byte(`{"latitude":3.1,"longitude":101.6}`),
+		geoToWKB(g),
+	}
+	for _, c := range checks {
+		result, err := parseGeoPoint(c)
+		if err != nil {
+			t.Fatalf("Unexpected err, %v", err)
+		}
+		if result != g {
+			t.Fatalf(errUnexpectedResult, "parseGeoPoint")
+		}
+	}
+	if _, err := parseGeoPoint([]byte("abc")); err == nil {
+		t.Fatal(`"parseGeoPoint" should return error on corrupted value`)
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	g := datastore.GeoPoint{Lat: 3.1, Lng: 101.6}
+	sw, ne := boundingBox(g, 1000)
+	if sw.Lat >= g.Lat || sw.Lng >= g.Lng || ne.Lat <= g.Lat || ne.Lng <= g.Lng {
+		t.Fatalf(errUnexpectedResult, "boundingBox")
+	}
+	if math.Abs((ne.Lat-sw.Lat)*metersPerLat-2000) > 1 {
+		t.Fatalf(errUnexpectedResult, "boundingBox")
+	}
+
+	sw, ne = boundingBox(datastore.GeoPoint{Lat: 89.9, Lng: 0}, 100000)
+	if !sw.Valid() || !ne.Valid() {
+		t.Fatalf(errUnexpectedResult, "boundingBox")
+	}
+}
+
+func TestConvertGeoColumn(t *testing.T) {
+	dialect := new(mysql)
+	newFakeDB(t, dialect)
+	fakeMu.Lock()
+	fakeStatements = fakeStatements[:0]
+	fakeMu.Unlock()
+	if err := dialect.convertGeoColumn("Store", Schema{Name: "Location", DataType: "point SRID 4326"}); err != nil {
+		t.Fatal(err)
+	}
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	if len(fakeStatements) != 3 || !strings.Contains(fakeStatements[1], "`Store` SET `Location_point` = ST_GeomFromText(") ||
+		!strings.HasSuffix(fakeStatements[2], "`Store` DROP COLUMN `Location`, RENAME COLUMN `Location_point` TO `Location`;") {
+		t.Fatalf("unexpected statements %v", fakeStatements)
+	}
+
+	using := new(postgres).usingExpr(Schema{Name: "Location", DataType: "point"}, "jsonb")
+	if using != `point(("Location"->>'longitude')::float8, ("Location"->>'latitude')::float8)` {
+		t.Fatalf(errUnexpectedResult, "usingExpr")
+	}
+	if new(postgres).usingExpr(Schema{Name: "Location", DataType: "point"}, "point") != "" {
+		t.Fatalf(errUnexpectedResult, "usingExpr")
+	}
+}
+
+func TestFilterGeoJSON(t *testing.T) {
+	g := datastore.GeoPoint{Lat: 3.1, Lng: 101.6}
+	near := Filter{field: "Location", operator: Near, value: geoNear{point: g, distance: 1000}}
+
+	// the geo point is stored as json before mysql 8.0
+	legacy := &sequel{version: "5.7.44-log"}
+	str, args, err := legacy.FilterGeo(near)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lng, lat := "CAST(`Location`->>'$.longitude' AS DECIMAL(11,8))", "CAST(`Location`->>'$.latitude' AS DECIMAL(10,8))"
+	if str != "("+lng+" BETWEEN ?? AND ?? AND "+lat+" BETWEEN ?? AND ?? AND ST_Distance_Sphere(POINT("+lng+", "+lat+"), POINT(??, ??)) <= ??)" ||
+		len(args) != 7 || args[4] != g.Lng || args[5] != g.Lat || args[6] != float64(1000) {
+		t.Fatalf("unexpected statement %s %v", str, args)
+	}
+	if legacy.GeoValue(g) != `{"latitude":3.1,"longitude":101.6}` {
+		t.Fatalf(errUnexpectedResult, "GeoValue")
+	}
+	schema := legacy.GetSchema(Column{names: []string{"Location"}, field: newField(tag{name: "Location"}, nil, nil, nil, typeOfGeoPoint, false, nil)})
+	if schema[0].DataType != "json" || schema[0].IsIndexed {
+		t.Fatalf(errUnexpectedResult, "GetSchema")
+	}
+
+	for _, d := range []*sequel{{version: "8.0.35"}, new(sequel)} {
+		str, _, err = d.FilterGeo(near)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(str, "(MBRContains(ST_GeomFromText(") {
+			t.Fatalf("unexpected statement %s", str)
+		}
+		if _, isOk := d.GeoValue(g).([]byte); !isOk {
+			t.Fatalf(errUnexpectedResult, "GeoValue")
+		}
+	}
+	if (&sequel{version: "10.6.12-MariaDB"}).hasSpatial() {
+		t.Fatal("mariadb should store geo point as json")
+	}
+}
@@ -0,0 +1,93 @@
+package goloquent
+
+import (
+	"database/sql/driver"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+type testMultiUser struct {
+	Key  *datastore.Key `goloquent:"__key__"`
+	Name string
+}
+
+func TestGetMultiError(t *testing.T) {
+	db := newFakeDB(t)
+	keys := []*datastore.Key{
+		datastore.NameKey("User", "u1", nil),
+		nil,
+		datastore.IncompleteKey("User", nil),
+		datastore.NameKey("Admin", "a1", nil),
+		datastore.NameKey("User", "u1", nil),
+	}
+
+	before := atomic.LoadInt64(&fakeQueried)
+	users := make([]*testMultiUser, 0)
+	err := db.GetMulti(keys, &users)
+	errs, isOk := err.(MultiError)
+	if !isOk || len(errs) != len(keys) || len(users) != len(keys) {
+		t.Fatalf(errUnexpectedResult, "GetMulti")
+	}
+	for _, i := range []int{0, 3, 4} {
+		if errs[i] != ErrNoSuchEntity || users[i] != nil {
+			t.Fatalf("missing entity at index %d should return ErrNoSuchEntity, %v", i, errs[i])
+		}
+	}
+	for _, i := range []int{1, 2} {
+		if errs[i] == nil || !strings.Contains(errs[i].Error(), "invalid key value") {
+			t.Fatalf("invalid key at index %d should return error, %v", i, errs[i])
+		}
+	}
+	// the keys are grouped into one query per table
+	if n := atomic.LoadInt64(&fakeQueried) - before; n != 2 {
+		t.Fatalf("keys should be queried once per table, but queried %d times", n)
+	}
+}
+
+func TestGetMultiChunk(t *testing.T) {
+	db := newFakeDB(t)
+	keys := make([]*datastore.Key, maxMultiKeys*2+1)
+	for i := range keys {
+		keys[i] = datastore.IDKey("User", int64(i+1), nil)
+	}
+
+	before := atomic.LoadInt64(&fakeQueried)
+	users := make([]testMultiUser, 0)
+	errs, _ := db.GetMulti(keys, &users).(MultiError)
+	if len(errs) != len(keys) || len(users) != len(keys) {
+		t.Fatalf(errUnexpectedResult, "GetMulti")
+	}
+	if n := atomic.LoadInt64(&fakeQueried) - before; n != 3 {
+		t.Fatalf("keys should be queried in 3 chunks, but queried %d times", n)
+	}
+}
+
+func TestGetMultiOrder(t *testing.T) {
+	db := newFakeDB(t)
+	// the rows are returned in the order of database instead of the order of keys
+	setFakeRows(t, []string{pkColumn, parentColumn, "Name"},
+		[]driver.Value{[]byte("'u1'"), []byte(""), []byte("first")},
+		[]driver.Value{[]byte("'u2'"), []byte(""), []byte("second")},
+	)
+
+	users := make([]testMultiUser, 0)
+	err := db.GetMulti([]*datastore.Key{
+		datastore.NameKey("User", "u2", nil),
+		datastore.NameKey("User", "u3", nil),
+		datastore.NameKey("User", "u1", nil),
+		datastore.NameKey("User", "u2", nil),
+	}, &users)
+	errs, isOk := err.(MultiError)
+	if !isOk || len(users) != 4 || errs[0] != nil || errs[1] != ErrNoSuchEntity || errs[2] != nil || errs[3] != nil {
+		t.Fatalf(errUnexpectedResult, "GetMulti")
+	}
+	if users[0].Name != "second" || users[1].Key != nil || users[2].Name != "first" || users[3].Name != "second" {
+		t.Fatal(`Unexpected result from "GetMulti", result should follow the order of keys`)
+	}
+	if users[0].Key == nil || users[0].Key.Name != "u2" || users[2].Key == nil || users[2].Key.Name != "u1" {
+		t.Fatal(`Unexpected result from "GetMulti", key should be loaded`)
+	}
+}
@@ -0,0 +1,245 @@
+package goloquent
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanAttributes : the attributes of the statement which is being executed
+type SpanAttributes struct {
+	Dialect   string
+	Operation Operation
+	Table     string
+	// Fingerprint is the hash of normalized statement, the statements which are only different on arguments have same fingerprint
+	Fingerprint string
+}
+
+// Span : the trace span of a statement, it's ended after the statement is executed
+type Span interface {
+	End(s *Stmt)
+}
+
+// Tracer : start the trace span when the statement is executed, the context is set by `DB.WithContext`,
+// so the span is able to be the child of the caller span, it's `context.Background` if it's not set
+type Tracer interface {
+	Start(ctx context.Context, attrs SpanAttributes) Span
+}
+
+// Metrics : observe the latency of every executed statement
+type Metrics interface {
+	Observe(attrs SpanAttributes, elapsed time.Duration, err error)
+}
+
+// instrument is the tracer and metrics which is shared by the clones of client
+type instrument struct {
+	mu      sync.RWMutex
+	tracer  Tracer
+	metrics Metrics
+}
+
+func (i *instrument) get() (Tracer, Metrics) {
+	if i == nil {
+		return nil, nil
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.tracer, i.metrics
+}
+
+func (db *DB) getInstrument() *instrument {
+	if db.client.instrument == nil {
+		db.client.instrument = new(instrument)
+	}
+	return db.client.instrument
+}
+
+// SetTracer : set the tracer which is invoked on every statement, nil will disable the tracing
+func (db *DB) SetTracer(t Tracer) {
+	i := db.getInstrument()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.tracer = t
+}
+
+// SetMetrics : set the metrics which observe every statement, nil will disable the metrics
+func (db *DB) SetMetrics(m Metrics) {
+	i := db.getInstrument()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.metrics = m
+}
+
+// WithContext : return the connection which the statements are traced with the context,
+// the context is only passed to `Tracer`, it will not cancel the statement
+func (db *DB) WithContext(ctx context.Context) *DB {
+	clone := db.clone()
+	clone.client.ctx = ctx
+	return clone
+}
+
+// Stats : the statistics of connection pool
+func (db *DB) Stats() sql.DBStats {
+	if conn, isOk := db.client.sqlCommon.(*sql.DB); isOk {
+		return conn.Stats()
+	}
+	// under transaction, the pool is kept by the statement cache
+	if db.client.stmts != nil {
+		return db.client.stmts.conn.Stats()
+	}
+	return sql.DBStats{}
+}
+
+// startTrace will stamp the start time and start the span of the statement
+func (c Client) startTrace(s *Stmt) {
+	s.startTrace()
+	if tracer, _ := c.instrument.get(); tracer != nil {
+		ctx := c.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		s.span = tracer.Start(ctx, c.spanAttributes(s))
+	}
+}
+
+// stopTrace will stamp the end time, end the span and log the statement
+func (c Client) stopTrace(s *Stmt) {
+	s.stopTrace()
+	if s.span != nil {
+		s.span.End(s)
+		s.span = nil
+	}
+	if _, metrics := c.instrument.get(); metrics != nil {
+		metrics.Observe(c.spanAttributes(s), s.TimeElapse(), s.err)
+	}
+	c.consoleLog(s)
+}
+
+func (c Client) spanAttributes(s *Stmt) SpanAttributes {
+	return SpanAttributes{
+		Dialect:     c.driver,
+		Operation:   s.crud,
+		Table:       s.table,
+		Fingerprint: s.Fingerprint(),
+	}
+}
+
+var (
+	rgxPlaceholders = regexp.MustCompile(`\?(\s*,\s*\?)+`)
+	rgxSpaces       = regexp.MustCompile(`\s+`)
+)
+
+// Fingerprint : the hash of normalized statement, the list of placeholders is collapsed, eg. `IN (?,?,?)` is same as `IN (?)`
+func (s *Stmt) Fingerprint() string {
+	str := strings.TrimSpace(rgxSpaces.ReplaceAllString(s.string(), " "))
+	str = rgxPlaceholders.ReplaceAllString(strings.ReplaceAll(str, variable, "?"), "?")
+	h := sha1.Sum([]byte(str))
+	return hex.EncodeToString(h[:8])
+}
+
+// RecordedSpan : the statement which is recorded by `Recorder`
+type RecordedSpan struct {
+	SpanAttributes
+	Elapsed      time.Duration
+	RowsAffected int64
+	RowsReturned int64
+	Err          error
+}
+
+// Recorder : the in-memory `Tracer` and `Metrics`, it's useful for testing
+type Recorder struct {
+	mu        sync.Mutex
+	spans     []RecordedSpan
+	latencies map[string][]time.Duration
+	failures  map[string]int
+	active    int
+}
+
+var (
+	_ Tracer  = (*Recorder)(nil)
+	_ Metrics = (*Recorder)(nil)
+)
+
+// NewRecorder :
+func NewRecorder() *Recorder {
+	return &Recorder{latencies: make(map[string][]time.Duration), failures: make(map[string]int)}
+}
+
+type recorderSpan struct {
+	r     *Recorder
+	attrs SpanAttributes
+}
+
+// End :
+func (s *recorderSpan) End(st *Stmt) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.r.active--
+	s.r.spans = append(s.r.spans, RecordedSpan{
+		SpanAttributes: s.attrs,
+		Elapsed:        st.TimeElapse(),
+		RowsAffected:   st.RowsAffected(),
+		RowsReturned:   st.RowsReturned(),
+		Err:            st.Err(),
+	})
+}
+
+// Start :
+func (r *Recorder) Start(ctx context.Context, attrs SpanAttributes) Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active++
+	return &recorderSpan{r: r, attrs: attrs}
+}
+
+// Observe :
+func (r *Recorder) Observe(attrs SpanAttributes, elapsed time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies[attrs.Table] = append(r.latencies[attrs.Table], elapsed)
+	if err != nil {
+		r.failures[attrs.Table]++
+	}
+}
+
+// Spans : the ended spans
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedSpan(nil), r.spans...)
+}
+
+// Active : number of spans which are not ended yet
+func (r *Recorder) Active() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.active
+}
+
+// Latencies : the observed latencies of the table
+func (r *Recorder) Latencies(table string) []time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]time.Duration(nil), r.latencies[table]...)
+}
+
+// Failures : number of the failed statements of the table
+func (r *Recorder) Failures(table string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures[table]
+}
+
+// Reset : clear all the records
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans, r.active = nil, 0
+	r.latencies = make(map[string][]time.Duration)
+	r.failures = make(map[string]int)
+}
@@ -0,0 +1,99 @@
+package goloquent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	db := newFakeDB(t)
+	r := NewRecorder()
+	db.SetTracer(r)
+	db.SetMetrics(r)
+
+	b := newBuilder(db.NewQuery())
+	if err := b.execWrite(OpUpdate, &stmt{
+		statement: bytes.NewBufferString("UPDATE `User` SET `Name` = ?? WHERE `Age` IN (??,??);"),
+		arguments: []interface{}{"Joe", 1, 2},
+	}, "User"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.run("Merchant", &stmt{statement: bytes.NewBufferString("SELECT * FROM `Merchant`;")}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := r.Spans()
+	if len(spans) != 2 || r.Active() != 0 {
+		t.Fatalf("expected 2 ended spans, but got %d", len(spans))
+	}
+	if s := spans[0]; s.Dialect != "fake" || s.Operation != OpUpdate || s.Table != "User" ||
+		s.RowsAffected != 1 || s.Fingerprint == "" {
+		t.Fatalf(errUnexpectedResult, "Recorder.Spans")
+	}
+	if s := spans[1]; s.Operation != OpGet || s.Table != "Merchant" {
+		t.Fatalf(errUnexpectedResult, "Recorder.Spans")
+	}
+	if len(r.Latencies("User")) != 1 || len(r.Latencies("Merchant")) != 1 {
+		t.Fatalf(errUnexpectedResult, "Recorder.Latencies")
+	}
+
+	r.Reset()
+	db.SetTracer(nil)
+	if _, err := b.run("Merchant", &stmt{statement: bytes.NewBufferString("SELECT * FROM `Merchant`;")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Spans()) != 0 || len(r.Latencies("Merchant")) != 1 {
+		t.Fatal("span shouldn't be recorded after tracer is removed")
+	}
+
+	if db.Stats().MaxOpenConnections != 0 {
+		t.Fatalf(errUnexpectedResult, "DB.Stats")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	s1 := &Stmt{stmt: stmt{statement: bytes.NewBufferString("SELECT * FROM `User` WHERE `Age` IN (??,??);")}}
+	s2 := &Stmt{stmt: stmt{statement: bytes.NewBufferString("SELECT *  FROM `User` WHERE `Age` IN (??, ??, ??);")}}
+	s3 := &Stmt{stmt: stmt{statement: bytes.NewBufferString("SELECT * FROM `Merchant`;")}}
+	if s1.Fingerprint() != s2.Fingerprint() || s1.Fingerprint() == s3.Fingerprint() {
+		t.Fatalf(errUnexpectedResult, "Stmt.Fingerprint")
+	}
+}
+
+type ctxKey struct{}
+
+type testTracer struct {
+	values []interface{}
+}
+
+func (tr *testTracer) Start(ctx context.Context, attrs SpanAttributes) Span {
+	tr.values = append(tr.values, ctx.Value(ctxKey{}))
+	return NewRecorder().Start(ctx, attrs)
+}
+
+func TestTracerContext(t *testing.T) {
+	db := newFakeDB(t)
+	tr := new(testTracer)
+	db.SetTracer(tr)
+	stmt := &stmt{statement: bytes.NewBufferString("SELECT * FROM `User`;")}
+	if _, err := newBuilder(db.NewQuery()).run("User", stmt); err != nil {
+		t.Fatal(err)
+	}
+	ctxDB := db.WithContext(context.WithValue(context.Background(), ctxKey{}, "span"))
+	if _, err := newBuilder(ctxDB.NewQuery()).run("User", stmt); err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.values) != 2 || tr.values[0] != nil || tr.values[1] != "span" {
+		t.Fatalf("unexpected context of tracer, %v", tr.values)
+	}
+
+	r := NewRecorder()
+	r.Observe(SpanAttributes{Table: "User"}, time.Millisecond, nil)
+	r.Observe(SpanAttributes{Table: "User"}, time.Millisecond, errors.New("goloquent: failed"))
+	if len(r.Latencies("User")) != 2 || r.Failures("User") != 1 {
+		t.Fatalf(errUnexpectedResult, "Recorder.Observe")
+	}
+}
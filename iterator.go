@@ -2,6 +2,7 @@ package goloquent
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -28,7 +29,10 @@ type Iterator struct {
 	sign     string
 	position int // current record position
 	columns  []string
+	types    map[string]string
 	results  []map[string][]byte
+	// timeConf is the storage of time, the loaded time is converted to the location
+	timeConf TimeConfig
 }
 
 func (it *Iterator) patchKey() {
@@ -59,7 +63,7 @@ func toByte(v interface{}) []byte {
 	switch vi := v.(type) {
 	case nil:
 	case time.Time:
-		b = []byte(vi.Format("2006-01-02 15:04:05"))
+		b = []byte(vi.UTC().Format(timeLayout))
 	case []byte:
 		b = vi
 	default:
@@ -79,6 +83,23 @@ func (it *Iterator) put(pos int, k string, v interface{}) error {
 	return nil
 }
 
+// readRow will read the current row of the rows into the position of the iterator
+func (it *Iterator) readRow(rows *sql.Rows, pos int) error {
+	m := make([]interface{}, len(it.columns))
+	for j := range it.columns {
+		m[j] = &m[j]
+	}
+
+	if err := rows.Scan(m...); err != nil {
+		return err
+	}
+
+	for j, name := range it.columns {
+		it.put(pos, name, m[j])
+	}
+	return nil
+}
+
 // First :
 func (it *Iterator) First() *Iterator {
 	it.position = 0
@@ -133,6 +154,21 @@ func (it *Iterator) Cursor() (Cursor, error) {
 	return c, nil
 }
 
+// Key : return the primary key of current record
+func (it *Iterator) Key() (*datastore.Key, error) {
+	if it.position < 0 || it.position > len(it.results)-1 {
+		return nil, fmt.Errorf("goloquent: interator out of index result range")
+	}
+	key, err := parseKey(string(it.results[it.position][keyFieldName]))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("goloquent: missing primary key")
+	}
+	return key, nil
+}
+
 // Next : go next record
 func (it *Iterator) Next() bool {
 	it.position++
@@ -147,6 +183,9 @@ func (it *Iterator) scan(src interface{}) (map[string]interface{}, error) {
 	if v.Type().Kind() != reflect.Ptr {
 		return nil, fmt.Errorf("goloquent: struct is not addressable")
 	}
+	if isDynamicType(v.Type().Elem()) {
+		return nil, it.loadDynamic(src)
+	}
 	codec, err := getStructCodec(src)
 	if err != nil {
 		return nil, err
@@ -168,6 +207,9 @@ func (it *Iterator) scan(src interface{}) (map[string]interface{}, error) {
 		}
 
 		vi := denormalize(f, props)
+		if loc := it.timeConf.location(f.tag); loc != time.UTC {
+			vi = inLocation(vi, loc)
+		}
 		data[f.name] = vi
 		if err := loadField(fv, vi); err != nil {
 			return nil, err
@@ -212,6 +254,7 @@ type Property struct {
 	name   []string
 	typeOf reflect.Type
 	Value  interface{}
+	tag    tag
 }
 
 func (p Property) isZero() bool {
@@ -255,7 +298,7 @@ func getTypes(ns []string, f field, isFlatten bool) []Property {
 		}
 	}
 
-	d := Property{append(ns, f.name), t, nil}
+	d := Property{append(ns, f.name), t, nil, f.tag}
 	props = append(props, d)
 	return props
 }
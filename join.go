@@ -0,0 +1,438 @@
+package goloquent
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type join struct {
+	kind  string
+	table string
+	alias string
+	left  string
+	op    string
+	right string
+}
+
+// source is the name to qualify the columns of the table, it's the alias if it has one
+func (j join) source() string {
+	if j.alias != "" {
+		return j.alias
+	}
+	return j.table
+}
+
+// parseTableAlias will split the table name and alias, eg. `Merchant AS m` or `Merchant m`
+func parseTableAlias(str string) (string, string, error) {
+	paths := strings.Fields(str)
+	switch {
+	case len(paths) == 1:
+		return paths[0], "", nil
+	case len(paths) == 2:
+		return paths[0], paths[1], nil
+	case len(paths) == 3 && strings.EqualFold(paths[1], "as"):
+		return paths[0], paths[2], nil
+	}
+	return "", "", fmt.Errorf("goloquent: invalid table name %q", str)
+}
+
+func joinOperator(op string) (string, error) {
+	switch strings.TrimSpace(strings.ToLower(op)) {
+	case "=", "eq":
+		return "=", nil
+	case "!=", "<>", "ne":
+		return "<>", nil
+	case ">", "gt":
+		return ">", nil
+	case ">=", "gte":
+		return ">=", nil
+	case "<", "lt":
+		return "<", nil
+	case "<=", "lte":
+		return "<=", nil
+	}
+	return "", fmt.Errorf("goloquent: invalid join operator %q", op)
+}
+
+// base is the name to qualify the columns of the query table
+func (s scope) base() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.table
+}
+
+// sources will return the table of each source (alias or table name) of the query
+func (s scope) sources() map[string]string {
+	m := map[string]string{s.base(): s.table}
+	for _, j := range s.joins {
+		m[j.source()] = j.table
+	}
+	return m
+}
+
+// splitField will split the qualified field name, eg. `m.Name`,
+// the qualifier is empty if the prefix is not a table or alias of the query
+func (s scope) splitField(name string) (string, string) {
+	if len(s.joins) <= 0 && s.alias == "" {
+		return "", name
+	}
+	paths := strings.SplitN(name, ".", 2)
+	if len(paths) < 2 {
+		return "", name
+	}
+	if _, isOk := s.sources()[paths[0]]; !isOk {
+		return "", name
+	}
+	return paths[0], paths[1]
+}
+
+func (b *builder) quoteColumn(qualifier, col string) string {
+	if qualifier == "" {
+		return b.db.dialect.Quote(col)
+	}
+	return b.db.dialect.Quote(qualifier) + "." + b.db.dialect.Quote(col)
+}
+
+// baseColumn will qualify the column with the query table when the query has join,
+// because the reserved columns are exists in every table
+func (b *builder) baseColumn(query scope, col string) string {
+	if len(query.joins) <= 0 {
+		return b.db.dialect.Quote(col)
+	}
+	return b.quoteColumn(query.base(), col)
+}
+
+// keyExpr will return the expression of full key path which same as the value of key column,
+// the primary key column doesn't store the kind if the primary key is simple
+func (b *builder) keyExpr(source, table string) string {
+	k := b.quoteColumn(source, pkColumn)
+	if !isPkSimple {
+		return k
+	}
+	p := fmt.Sprintf("COALESCE(%s, '')", b.quoteColumn(source, parentColumn))
+	return fmt.Sprintf("CONCAT(CASE WHEN %s = '' THEN '' ELSE CONCAT(%s, '%s') END, '%s', "+
+		"SUBSTRING(%s, LENGTH(%s) + CASE WHEN %s = '' THEN 1 ELSE 2 END))",
+		p, p, keyDelimeter, escapeSingleQuote(table+","), k, p, p)
+}
+
+func (b *builder) buildJoinField(query scope, name string, isKeyValue bool) (string, error) {
+	qualifier, field := query.splitField(name)
+	if qualifier == "" {
+		return "", fmt.Errorf("goloquent: join field %q must be qualified with table or alias", name)
+	}
+	switch field {
+	case keyFieldName, pkColumn:
+		if isKeyValue {
+			return b.keyExpr(qualifier, query.sources()[qualifier]), nil
+		}
+		return b.quoteColumn(qualifier, pkColumn), nil
+	}
+	return b.quoteColumn(qualifier, field), nil
+}
+
+func isKeyField(query scope, name string) bool {
+	_, field := query.splitField(name)
+	return field == keyFieldName || field == pkColumn
+}
+
+func (b *builder) buildFrom(query scope) (*stmt, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(" FROM " + b.db.dialect.GetTable(query.table))
+	if query.alias != "" {
+		buf.WriteString(" " + b.db.dialect.Quote(query.alias))
+	}
+	for _, j := range query.joins {
+		buf.WriteString(fmt.Sprintf(" %s %s", j.kind, b.db.dialect.GetTable(j.table)))
+		if j.alias != "" {
+			buf.WriteString(" " + b.db.dialect.Quote(j.alias))
+		}
+
+		// key column is comparing with the full key path, unless both are primary key
+		l, r := isKeyField(query, j.left), isKeyField(query, j.right)
+		left, err := b.buildJoinField(query, j.left, l != r)
+		if err != nil {
+			return nil, err
+		}
+		right, err := b.buildJoinField(query, j.right, l != r)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(fmt.Sprintf(" ON %s %s %s", left, j.op, right))
+		if b.hasSoftDelete(j.table) {
+			buf.WriteString(fmt.Sprintf(" AND %s IS NULL", b.quoteColumn(j.source(), softDeleteColumn)))
+		}
+	}
+	return &stmt{statement: buf}, nil
+}
+
+// hasSoftDelete is cached by table, so the columns are not queried on every statement
+func (b *builder) hasSoftDelete(table string) bool {
+	if b.db.softDeletes != nil {
+		if v, isOk := b.db.softDeletes.Load(table); isOk {
+			return v.(bool)
+		}
+	}
+	isExist := newDictionary(b.db.dialect.GetColumns(table)).has(softDeleteColumn)
+	if b.db.softDeletes != nil {
+		b.db.softDeletes.Store(table, isExist)
+	}
+	return isExist
+}
+
+// hasParent is cached by table, the `$Parent` column is only created by `Migrate`
+func (b *builder) hasParent(table string) bool {
+	if b.db.parents != nil {
+		if v, isOk := b.db.parents.Load(table); isOk {
+			return v.(bool)
+		}
+	}
+	isExist := newDictionary(b.db.dialect.GetColumns(table)).has(parentColumn)
+	if b.db.parents != nil {
+		b.db.parents.Store(table, isExist)
+	}
+	return isExist
+}
+
+// resetColumns will remove the cache of soft delete and parent column, it must be called when the table schema is changed
+func (db *DB) resetColumns(tables ...string) {
+	for _, t := range tables {
+		if db.softDeletes != nil {
+			db.softDeletes.Delete(t)
+		}
+		if db.parents != nil {
+			db.parents.Delete(t)
+		}
+	}
+}
+
+type joinTarget struct {
+	paths    []int
+	typeOf   reflect.Type
+	source   string
+	table    string
+	isModel  bool
+	isPtr    bool
+	columns  []string
+	property Property
+}
+
+// joinTargets will resolve the fields of join result struct, the struct field (or embedded struct) with
+// the name of table or alias will decode as model, and the field with qualified name will decode as column
+func joinTargets(query scope, t reflect.Type) ([]joinTarget, error) {
+	sources := query.sources()
+	targets := make([]joinTarget, 0)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag := newTag(sf)
+		if tag.isSkip() {
+			continue
+		}
+		ft, isPtr := sf.Type, false
+		if ft.Kind() == reflect.Ptr {
+			ft, isPtr = ft.Elem(), true
+		}
+		table, isModel := sources[tag.name]
+		if isModel && ft.Kind() == reflect.Struct && !isBaseType(ft) {
+			codec, err := getStructCodec(reflect.New(ft).Interface())
+			if err != nil {
+				return nil, err
+			}
+			cols := make([]string, 0)
+			for _, c := range getColumns(nil, codec) {
+				name := c.Name()
+				if name == keyFieldName {
+					name = pkColumn
+				}
+				cols = append(cols, name)
+			}
+			targets = append(targets, joinTarget{
+				paths: sf.Index, typeOf: ft, source: tag.name, table: table,
+				isModel: true, isPtr: isPtr, columns: cols,
+			})
+			continue
+		}
+		qualifier, _ := query.splitField(tag.name)
+		targets = append(targets, joinTarget{
+			paths: sf.Index, typeOf: sf.Type, source: qualifier,
+			property: Property{name: []string{tag.name}, typeOf: sf.Type},
+		})
+	}
+	if len(targets) <= 0 {
+		return nil, fmt.Errorf("goloquent: join result %v doesn't has any field", t)
+	}
+	return targets, nil
+}
+
+func (b *builder) buildJoinSelect(query scope, targets []joinTarget) *stmt {
+	buf := new(bytes.Buffer)
+	buf.WriteString("SELECT ")
+	for _, t := range targets {
+		if !t.isModel {
+			qualifier, field := query.splitField(t.property.Name())
+			col := b.quoteColumn(qualifier, field)
+			if qualifier != "" && (field == keyFieldName || field == pkColumn) {
+				col = b.keyExpr(qualifier, query.sources()[qualifier])
+			}
+			buf.WriteString(fmt.Sprintf("%s AS %s,", col, b.db.dialect.Quote(t.property.Name())))
+			continue
+		}
+		for _, c := range t.columns {
+			buf.WriteString(fmt.Sprintf("%s AS %s,",
+				b.quoteColumn(t.source, c), b.db.dialect.Quote(t.source+"."+c)))
+		}
+	}
+	buf.Truncate(buf.Len() - 1)
+	return &stmt{statement: buf}
+}
+
+// prefixed will return the iterator of current record which only contains the columns with the prefix,
+// the prefix will be trimmed. It returns nil if the record is not exists, eg. left join without match
+func (it *Iterator) prefixed(prefix, table string) *Iterator {
+	cols := make([]string, 0)
+	l := make(map[string][]byte)
+	for _, c := range it.columns {
+		if strings.HasPrefix(c, prefix) {
+			k := strings.TrimPrefix(c, prefix)
+			cols = append(cols, k)
+			l[k] = it.results[it.position][c]
+		}
+	}
+	if l[pkColumn] == nil {
+		return nil
+	}
+	nit := &Iterator{table: table, position: 0, columns: cols, results: []map[string][]byte{l}, timeConf: it.timeConf}
+	nit.patchKey()
+	return nit
+}
+
+// joinModel will return the struct type of the join result model
+func joinModel(model interface{}) (t reflect.Type, isMulti, isPtr bool, err error) {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr {
+		return nil, false, false, fmt.Errorf("goloquent: model is not addressable")
+	}
+	t = v.Type().Elem()
+	if t.Kind() == reflect.Slice {
+		t, isMulti = t.Elem(), true
+		if t.Kind() == reflect.Ptr {
+			t, isPtr = t.Elem(), true
+		}
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false, false, fmt.Errorf("goloquent: invalid join result data type : %v, it should be struct", t)
+	}
+	return
+}
+
+// joinStmt will return the select statement of join query and the scan targets of the result model
+func (b *builder) joinStmt(t reflect.Type) (*stmt, []joinTarget, error) {
+	query := b.query
+	if query.table == "" {
+		return nil, nil, fmt.Errorf("goloquent: unable to perform join without table name")
+	}
+	targets, err := joinTargets(query, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !query.noScope && b.hasSoftDelete(query.table) {
+		query.filters = append(query.filters, Filter{
+			field:    query.base() + "." + softDeleteColumn,
+			operator: Equal,
+			value:    nil,
+		})
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(b.buildJoinSelect(query, targets).string())
+	from, err := b.buildFrom(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	buf.WriteString(from.string())
+	cmd, err := b.buildStmt(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	buf.WriteString(cmd.string() + ";")
+	return &stmt{statement: buf, arguments: cmd.arguments}, targets, nil
+}
+
+func (b *builder) getJoin(model interface{}, mustExist bool) error {
+	t, isMulti, isPtr, err := joinModel(model)
+	if err != nil {
+		return err
+	}
+	cmd, targets, err := b.joinStmt(t)
+	if err != nil {
+		return err
+	}
+	it, err := b.run(b.query.table, cmd)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(model)
+	vv := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), 0, 0)
+	if isMulti {
+		vv = reflect.MakeSlice(v.Type().Elem(), 0, int(it.Count()))
+	}
+	for it.Next() {
+		vi := reflect.New(t)
+		if err := it.scanJoin(vi.Elem(), targets); err != nil {
+			return err
+		}
+		if !isPtr {
+			vi = vi.Elem()
+		}
+		vv = reflect.Append(vv, vi)
+	}
+
+	switch {
+	case isMulti:
+		v.Elem().Set(vv)
+	case vv.Len() > 0:
+		v.Elem().Set(vv.Index(0))
+	case mustExist:
+		return ErrNoSuchEntity
+	default:
+		v.Elem().Set(reflect.Zero(t))
+	}
+	return nil
+}
+
+func (it *Iterator) scanJoin(v reflect.Value, targets []joinTarget) error {
+	for _, t := range targets {
+		fv := v.FieldByIndex(t.paths)
+		if !t.isModel {
+			vi, err := valueToInterface(t.typeOf, it.Get(t.property.Name()), false)
+			if err != nil {
+				return err
+			}
+			if err := loadField(fv, vi); err != nil {
+				return err
+			}
+			continue
+		}
+
+		nit := it.prefixed(t.source+".", t.table)
+		if nit == nil {
+			continue
+		}
+		mv := reflect.New(t.typeOf)
+		if _, err := nit.scan(mv.Interface()); err != nil {
+			return err
+		}
+		if t.isPtr {
+			fv.Set(mv)
+		} else {
+			fv.Set(mv.Elem())
+		}
+	}
+	return nil
+}
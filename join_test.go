@@ -0,0 +1,149 @@
+package goloquent
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+func TestParseTableAlias(t *testing.T) {
+	tables := map[string][2]string{
+		"Merchant":        {"Merchant", ""},
+		"Merchant m":      {"Merchant", "m"},
+		" Merchant AS m ": {"Merchant", "m"},
+		"Merchant as m":   {"Merchant", "m"},
+	}
+	for str, expect := range tables {
+		table, alias, err := parseTableAlias(str)
+		if err != nil || table != expect[0] || alias != expect[1] {
+			t.Fatalf(errUnexpectedResult, "parseTableAlias")
+		}
+	}
+	if _, _, err := parseTableAlias("Merchant IS m"); err == nil {
+		t.Fatal(`"parseTableAlias" should return error on invalid table name`)
+	}
+	if _, err := joinOperator("like"); err == nil {
+		t.Fatal(`"joinOperator" should return error on invalid operator`)
+	}
+}
+
+func TestSplitField(t *testing.T) {
+	query := scope{
+		table: "User",
+		alias: "u",
+		joins: []join{{kind: "LEFT JOIN", table: "Merchant", alias: "m"}},
+	}
+	fields := map[string][2]string{
+		"u.Name":        {"u", "Name"},
+		"m.__key__":     {"m", "__key__"},
+		"Address.City":  {"", "Address.City"},
+		"Name":          {"", "Name"},
+		"Merchant.Name": {"", "Merchant.Name"},
+	}
+	for name, expect := range fields {
+		qualifier, field := query.splitField(name)
+		if qualifier != expect[0] || field != expect[1] {
+			t.Fatalf(errUnexpectedResult, "splitField")
+		}
+	}
+
+	if qualifier, _ := (scope{table: "User"}).splitField("User.Name"); qualifier != "" {
+		t.Fatal(`"splitField" shouldn't qualify field without join or alias`)
+	}
+}
+
+func TestBuildJoin(t *testing.T) {
+	b := &builder{db: &DB{dialect: new(sequel), parents: new(sync.Map)}}
+	b.db.parents.Store("User", true)
+	query := scope{
+		table: "User",
+		alias: "u",
+		joins: []join{{kind: "INNER JOIN", table: "Merchant", alias: "m"}},
+	}
+	query.filters = append(query.filters,
+		Filter{field: "m.Name", operator: Equal, value: "Shop"},
+		Filter{field: "m.__key__", operator: Equal, value: datastore.NameKey("Merchant", "m1", nil)},
+	)
+	query.ancestors = append(query.ancestors, group{false, []interface{}{datastore.IDKey("Parent", 1, nil)}, 1})
+
+	cmd, err := b.buildWhere(query)
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if cmd.string() != " WHERE `m`.`Name` = ?? AND `m`.`$Key` = ?? AND `u`.`$Parent` = ??" ||
+		!reflect.DeepEqual(cmd.arguments, []interface{}{"Shop", "'m1'", "Parent,1"}) {
+		t.Fatalf(errUnexpectedResult, "buildWhere")
+	}
+
+	if b.keyExpr("m", "Merchant") != "CONCAT(CASE WHEN COALESCE(`m`.`$Parent`, '') = '' THEN '' "+
+		"ELSE CONCAT(COALESCE(`m`.`$Parent`, ''), '/') END, 'Merchant,', "+
+		"SUBSTRING(`m`.`$Key`, LENGTH(COALESCE(`m`.`$Parent`, '')) + "+
+		"CASE WHEN COALESCE(`m`.`$Parent`, '') = '' THEN 1 ELSE 2 END))" {
+		t.Fatalf(errUnexpectedResult, "keyExpr")
+	}
+}
+
+func TestIteratorPrefixed(t *testing.T) {
+	it := &Iterator{
+		position: 0,
+		columns:  []string{"u.$Key", "u.Name", "m.$Key", "m.Name"},
+		results: []map[string][]byte{{
+			"u.$Key": []byte("'u1'"), "u.Name": []byte("Joe"),
+			"m.$Key": nil, "m.Name": nil,
+		}},
+	}
+	nit := it.prefixed("u.", "User")
+	if nit == nil || string(nit.Get("Name")) != "Joe" || string(nit.Get(keyFieldName)) != "/User,'u1'" {
+		t.Fatalf(errUnexpectedResult, "prefixed")
+	}
+	if it.prefixed("m.", "Merchant") != nil {
+		t.Fatal(`"prefixed" should return nil when the joined record is not exists`)
+	}
+}
+
+func TestJoinUnsupported(t *testing.T) {
+	db := newFakeDB(t)
+	q := db.Table("User u").Join("Merchant m", "u.Merchant", "=", "m.__key__")
+	if err := q.Paginate(&Pagination{Limit: 10}, &[]testUser{}); err == nil {
+		t.Fatal(`join should return error on "Paginate"`)
+	}
+	if err := q.Find(datastore.IDKey("User", 1, nil), &testUser{}); err == nil {
+		t.Fatal(`join should return error on "Find"`)
+	}
+	if _, err := q.Keys(); err == nil {
+		t.Fatal(`join should return error on "Keys"`)
+	}
+	if err := q.Update(map[string]interface{}{"Name": "x"}); err == nil {
+		t.Fatal(`join should return error on "Update"`)
+	}
+	if err := q.Flush(); err == nil {
+		t.Fatal(`join should return error on "Flush"`)
+	}
+	if _, err := q.ToSQL(OpUpdate, map[string]interface{}{"Name": "x"}); err == nil {
+		t.Fatal(`join should return error on "ToSQL"`)
+	}
+}
+
+func TestSoftDeleteCache(t *testing.T) {
+	db := newFakeDB(t)
+	b := newBuilder(db.NewQuery())
+	before := atomic.LoadInt64(&fakeQueried)
+	b.hasSoftDelete("User")
+	queried := atomic.LoadInt64(&fakeQueried) - before
+	for i := 0; i < 3; i++ {
+		if b.hasSoftDelete("User") {
+			t.Fatal("table should not have soft delete column")
+		}
+	}
+	if atomic.LoadInt64(&fakeQueried)-before != queried {
+		t.Fatal("columns of table should be cached")
+	}
+	db.resetColumns("User")
+	b.hasSoftDelete("User")
+	if atomic.LoadInt64(&fakeQueried)-before != queried*2 {
+		t.Fatal("columns of table should be queried after reset")
+	}
+}
@@ -0,0 +1,119 @@
+package goloquent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type jsonOperator int
+
+// json update operators :
+const (
+	jsonSet jsonOperator = iota
+	jsonRemove
+	jsonArrayAppend
+)
+
+// JSONUpdate : is the partial update operation of json path on `Update`
+type JSONUpdate struct {
+	op    jsonOperator
+	value interface{}
+}
+
+// JSONSet : set the value of json path, it's the default operation if the value is not `JSONUpdate`
+func JSONSet(v interface{}) JSONUpdate {
+	return JSONUpdate{jsonSet, v}
+}
+
+// JSONRemove : remove the json path
+func JSONRemove() JSONUpdate {
+	return JSONUpdate{op: jsonRemove}
+}
+
+// JSONArrayAppend : append the value to the json array of json path
+func JSONArrayAppend(v interface{}) JSONUpdate {
+	return JSONUpdate{jsonArrayAppend, v}
+}
+
+// Value : return the json encoded value
+func (u JSONUpdate) Value() (json.RawMessage, error) {
+	v, err := normalizeValue(u.value)
+	if err != nil {
+		return nil, err
+	}
+	v, err = interfaceToValue(v)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("goloquent: unable to marshal json value %v", u.value)
+	}
+	return b, nil
+}
+
+type jsonPath struct {
+	paths  []string
+	update JSONUpdate
+}
+
+// splitJSONPath will split the update key `Address>region>regionCode` to the column and the paths,
+// both json delimeter and dot are accepted as path separator
+func splitJSONPath(k string) (string, []string, error) {
+	paths := strings.SplitN(k, jsonDelimeter, 2)
+	col := strings.TrimSpace(paths[0])
+	if len(paths) < 2 || col == "" {
+		return "", nil, fmt.Errorf("goloquent: invalid json path %q", k)
+	}
+	list := strings.Split(strings.Replace(paths[1], jsonDelimeter, ".", -1), ".")
+	for i := range list {
+		list[i] = strings.TrimSpace(list[i])
+		if list[i] == "" {
+			return "", nil, fmt.Errorf("goloquent: invalid json path %q", k)
+		}
+	}
+	return col, list, nil
+}
+
+// groupJSONPaths will group the json paths by column, the result is sorted by column and key
+func groupJSONPaths(m map[string]interface{}) ([]string, map[string][]jsonPath, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	cols := make([]string, 0)
+	group := make(map[string][]jsonPath)
+	for _, k := range keys {
+		col, paths, err := splitJSONPath(k)
+		if err != nil {
+			return nil, nil, err
+		}
+		u, isOk := m[k].(JSONUpdate)
+		if !isOk {
+			u = JSONSet(m[k])
+		}
+		if _, isExist := group[col]; !isExist {
+			cols = append(cols, col)
+		}
+		group[col] = append(group[col], jsonPath{paths, u})
+	}
+	return cols, group, nil
+}
+
+// mysqlJSONPath will convert the paths to mysql json path expression, eg. `$."region"."regionCode"`
+func mysqlJSONPath(paths []string) string {
+	buf := new(strings.Builder)
+	buf.WriteString("$")
+	for _, p := range paths {
+		if _, err := strconv.ParseUint(p, 10, 64); err == nil {
+			buf.WriteString("[" + p + "]")
+			continue
+		}
+		buf.WriteString("." + strconv.Quote(p))
+	}
+	return buf.String()
+}
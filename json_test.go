@@ -0,0 +1,146 @@
+package goloquent
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+func TestSplitJSONPath(t *testing.T) {
+	checks := map[string][]string{
+		"Address>region>regionCode": {"region", "regionCode"},
+		"Address>region.regionCode": {"region", "regionCode"},
+		"Address>Emails>0":          {"Emails", "0"},
+	}
+	for k, paths := range checks {
+		col, result, err := splitJSONPath(k)
+		if err != nil {
+			t.Fatalf("Unexpected err, %v", err)
+		}
+		if col != "Address" || !reflect.DeepEqual(result, paths) {
+			t.Fatalf(errUnexpectedResult, k)
+		}
+	}
+	for _, k := range []string{"Address", ">region", "Address>", "Address>region..code"} {
+		if _, _, err := splitJSONPath(k); err == nil {
+			t.Fatalf(`"splitJSONPath" should return error on %q`, k)
+		}
+	}
+}
+
+func TestMySQLJSONPath(t *testing.T) {
+	if mysqlJSONPath([]string{"region", "regionCode"}) != `$."region"."regionCode"` {
+		t.Fatalf(errUnexpectedResult, "mysqlJSONPath")
+	}
+	if mysqlJSONPath([]string{"Emails", "0"}) != `$."Emails"[0]` {
+		t.Fatalf(errUnexpectedResult, "mysqlJSONPath")
+	}
+}
+
+func TestUpdateJSON(t *testing.T) {
+	cols, group, err := groupJSONPaths(map[string]interface{}{
+		"Address>region>regionCode": "MY",
+		"Address>Line2":             JSONRemove(),
+		"Emails>list":               JSONArrayAppend("a@b.com"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if !reflect.DeepEqual(cols, []string{"Address", "Emails"}) || len(group["Address"]) != 2 {
+		t.Fatalf(errUnexpectedResult, "groupJSONPaths")
+	}
+
+	s := new(sequel)
+	expr, args := "`Address`", make([]interface{}, 0)
+	for _, p := range group["Address"] {
+		expr, args, err = s.UpdateJSON(expr, args, p.paths, p.update)
+		if err != nil {
+			t.Fatalf("Unexpected err, %v", err)
+		}
+	}
+	if expr != "JSON_SET(JSON_REMOVE(`Address`, ??), ??, CAST(?? AS JSON))" ||
+		!reflect.DeepEqual(args, []interface{}{`$."Line2"`, `$."region"."regionCode"`, `"MY"`}) {
+		t.Fatalf(errUnexpectedResult, "UpdateJSON")
+	}
+
+	p := new(postgres)
+	jp := group["Emails"][0]
+	expr, args, err = p.UpdateJSON(`"Emails"`, nil, jp.paths, jp.update)
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if expr != `jsonb_set("Emails", ARRAY[??]::text[], COALESCE("Emails" #> ARRAY[??]::text[], '[]'::jsonb) || ??::jsonb, true)` ||
+		!reflect.DeepEqual(args, []interface{}{"list", "list", `["a@b.com"]`}) {
+		t.Fatalf(errUnexpectedResult, "UpdateJSON")
+	}
+}
+
+type testAddressBook struct {
+	Key     *datastore.Key `goloquent:"__key__"`
+	Address struct {
+		Region string `goloquent:"region"`
+	}
+	Contact struct {
+		Email string `goloquent:"email"`
+	} `goloquent:",index=email"`
+}
+
+func TestFilterJSONIn(t *testing.T) {
+	db := newFakeDB(t)
+	checks := []struct {
+		query *Query
+		raw   string
+		args  []interface{}
+	}{
+		// the json path without index keep the `JSON_CONTAINS` semantics, it's matching the member of json array
+		{
+			db.NewQuery().WhereJSONIn("Address>region", []interface{}{"a", true, 1, []string{"x"}}),
+			"(JSON_CONTAINS(`Address`->>\"$.region\", ?) OR JSON_CONTAINS(`Address`->>\"$.region\", ?) OR " +
+				"JSON_CONTAINS(`Address`->>\"$.region\", ?) OR JSON_CONTAINS(`Address`->>\"$.region\", ?))",
+			[]interface{}{json.RawMessage(`"a"`), json.RawMessage(`true`), json.RawMessage(`1`), json.RawMessage(`["x"]`)},
+		},
+		{
+			db.NewQuery().WhereJSONNotIn("Address>region", []interface{}{"a", false}),
+			"(NOT JSON_CONTAINS(`Address`->>\"$.region\", ?) AND NOT JSON_CONTAINS(`Address`->>\"$.region\", ?))",
+			[]interface{}{json.RawMessage(`"a"`), json.RawMessage(`false`)},
+		},
+		// the indexed json path is compared with the unquoted text, same as the generated column
+		{
+			db.NewQuery().WhereJSONIn("Contact>email", []interface{}{"a", true, 1}),
+			"(`Contact`->>\"$.email\") IN (?,?,?)",
+			[]interface{}{"a", "true", "1"},
+		},
+		{
+			db.NewQuery().WhereJSONNotIn("Contact>email", []interface{}{"a", true, 1}),
+			"(`Contact`->>\"$.email\") NOT IN (?,?,?)",
+			[]interface{}{"a", "true", "1"},
+		},
+	}
+	for _, c := range checks {
+		s, err := c.query.ToSQL(OpGet, &[]testAddressBook{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(s.Raw(), c.raw) || !reflect.DeepEqual(s.Arguments(), c.args) {
+			t.Fatalf("unexpected statement %s %v", s.Raw(), s.Arguments())
+		}
+	}
+}
+
+func TestPostgresFilterJSONIn(t *testing.T) {
+	db := newFakeDB(t, new(postgres))
+	s, err := db.NewQuery().
+		WhereJSONIn("Address>region", []interface{}{"a", true, []string{"x"}}).
+		ToSQL(OpGet, &[]testAddressBook{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := []interface{}{json.RawMessage(`"a"`), json.RawMessage(`true`), json.RawMessage(`["x"]`)}
+	if !strings.Contains(s.Raw(), `(("Address"->'region' = $1) OR ("Address"->'region' = $2) OR ("Address"->'region' = $3))`) ||
+		!reflect.DeepEqual(s.Arguments(), args) {
+		t.Fatalf("unexpected statement %s %v", s.Raw(), s.Arguments())
+	}
+}
@@ -0,0 +1,38 @@
+package goloquent
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+var txSeq uint64
+
+// newTxID will generate the unique id of transaction within the process
+func newTxID() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(atomic.AddUint64(&txSeq, 1), 36)
+}
+
+// ChainLogHandler : combine the log handlers, the statement is passed to every handler in order
+func ChainLogHandler(handlers ...LogHandler) LogHandler {
+	hs := make([]LogHandler, 0, len(handlers))
+	for _, h := range handlers {
+		if h != nil {
+			hs = append(hs, h)
+		}
+	}
+	return func(s *Stmt) {
+		for _, h := range hs {
+			h(s)
+		}
+	}
+}
+
+// SlowQueryHandler : only pass the statement to handler when it's slower than the threshold or it's failed
+func SlowQueryHandler(threshold time.Duration, h LogHandler) LogHandler {
+	return func(s *Stmt) {
+		if s.Err() != nil || s.TimeElapse() >= threshold {
+			h(s)
+		}
+	}
+}
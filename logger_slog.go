@@ -0,0 +1,36 @@
+//go:build go1.21
+// +build go1.21
+
+package goloquent
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler : log the statement with `log/slog`, failed statement is logged with error level
+func SlogHandler(l *slog.Logger) LogHandler {
+	if l == nil {
+		l = slog.Default()
+	}
+	return func(s *Stmt) {
+		attrs := []slog.Attr{
+			slog.String("operation", string(s.Operation())),
+			slog.String("table", s.Table()),
+			slog.String("sql", s.Raw()),
+			slog.Duration("elapsed", s.TimeElapse()),
+			slog.Int64("rows_affected", s.RowsAffected()),
+			slog.Int64("rows_returned", s.RowsReturned()),
+			slog.String("caller", s.Caller()),
+		}
+		if s.TxID() != "" {
+			attrs = append(attrs, slog.String("tx_id", s.TxID()))
+		}
+		level, msg := slog.LevelDebug, "goloquent: statement executed"
+		if err := s.Err(); err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+			level, msg = slog.LevelError, "goloquent: statement failed"
+		}
+		l.LogAttrs(context.Background(), level, msg, attrs...)
+	}
+}
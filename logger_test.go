@@ -0,0 +1,62 @@
+package goloquent
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStmtTelemetry(t *testing.T) {
+	stmts := make([]*Stmt, 0)
+	db := newFakeDB(t)
+	db.client.logger = func(s *Stmt) {
+		stmts = append(stmts, s)
+	}
+
+	b := newBuilder(db.Table("User").WhereEqual("Name", "Joe"))
+	if err := b.execWrite(OpFlush, &stmt{statement: bytes.NewBufferString("DELETE FROM `User`;")}, "User"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.run("User", &stmt{statement: bytes.NewBufferString("SELECT * FROM `User`;")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("expected 2 statements logged, but got %d", len(stmts))
+	}
+	if s := stmts[0]; s.Operation() != OpFlush || s.Table() != "User" || s.RowsAffected() != 1 ||
+		s.Err() != nil || s.TxID() != "" || !strings.Contains(s.Caller(), "logger_test.go:") {
+		t.Fatalf(errUnexpectedResult, "Stmt telemetry of write")
+	}
+	if s := stmts[1]; s.Operation() != OpGet || s.Table() != "User" || s.RowsReturned() != 0 || s.RowsAffected() != -1 {
+		t.Fatalf(errUnexpectedResult, "Stmt telemetry of query")
+	}
+
+	stmts = stmts[:0]
+	if err := newBuilder(db.NewQuery()).runInTransaction(func(tx *DB) error {
+		return newBuilder(tx.NewQuery()).execWrite(OpTruncate, &stmt{statement: bytes.NewBufferString("TRUNCATE TABLE `User`;")}, "User")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 1 || stmts[0].TxID() == "" {
+		t.Fatal("statement in transaction should have transaction id")
+	}
+}
+
+func TestSlowQueryHandler(t *testing.T) {
+	logged := 0
+	h := ChainLogHandler(nil, SlowQueryHandler(time.Second, func(s *Stmt) {
+		logged++
+	}))
+	now := time.Now()
+	h(&Stmt{startTime: now, endTime: now.Add(time.Millisecond)})
+	if logged != 0 {
+		t.Fatal("fast statement shouldn't be logged")
+	}
+	h(&Stmt{startTime: now, endTime: now.Add(2 * time.Second)})
+	h(&Stmt{startTime: now, endTime: now, err: errors.New("failed")})
+	if logged != 2 {
+		t.Fatal("slow or failed statement should be logged")
+	}
+}
@@ -0,0 +1,567 @@
+package goloquent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+// isDynamicType : entity which implement `datastore.PropertyLoadSaver` is schemaless,
+// it's properties will map to the table columns and the rest will store in overflow column
+func isDynamicType(t reflect.Type) bool {
+	return t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(typeOfPropertyLoadSaver)
+}
+
+func newDynamicEntity(t reflect.Type, v reflect.Value) *entity {
+	key := Column{
+		names: []string{keyFieldName},
+		field: newField(tag{name: keyFieldName}, nil, nil, nil, typeOfPtrKey, false, nil),
+	}
+	overflow := Column{
+		names: []string{overflowColumn},
+		field: newField(tag{name: overflowColumn}, nil, nil, nil, typeOfJSONRawMessage, true, nil),
+	}
+	return &entity{
+		name:       t.Name(),
+		typeOf:     t,
+		isMultiPtr: true,
+		isDynamic:  true,
+		slice:      v,
+		fields:     map[string]Column{keyFieldName: key},
+		columns:    []Column{key, parentKeyColumn(), overflow},
+	}
+}
+
+func saveProperties(v reflect.Value) ([]datastore.Property, error) {
+	pls, isOk := v.Interface().(datastore.PropertyLoadSaver)
+	if !isOk {
+		return nil, fmt.Errorf("goloquent: %v is not implement PropertyLoadSaver", v.Type())
+	}
+	props, err := pls.Save()
+	if err != nil {
+		return nil, fmt.Errorf("goloquent: %v", err)
+	}
+	return props, nil
+}
+
+// splitKeyProperty will extract the primary key from the `__key__` property
+func splitKeyProperty(props []datastore.Property) (*datastore.Key, []datastore.Property) {
+	var k *datastore.Key
+	list := make([]datastore.Property, 0, len(props))
+	for _, p := range props {
+		if p.Name == keyFieldName {
+			k, _ = p.Value.(*datastore.Key)
+			continue
+		}
+		list = append(list, p)
+	}
+	return k, list
+}
+
+func dynamicKey(v reflect.Value) (*datastore.Key, error) {
+	props, err := saveProperties(v)
+	if err != nil {
+		return nil, err
+	}
+	k, _ := splitKeyProperty(props)
+	return k, nil
+}
+
+// setDynamicKey will assign the primary key back to the entity
+func setDynamicKey(v reflect.Value, k *datastore.Key) error {
+	if x, isOk := v.Interface().(datastore.KeyLoader); isOk {
+		if err := x.LoadKey(k); err != nil {
+			return fmt.Errorf("goloquent: %v", err)
+		}
+		return nil
+	}
+	if l, isOk := v.Interface().(*datastore.PropertyList); isOk {
+		_, props := splitKeyProperty(*l)
+		*l = append(datastore.PropertyList{{Name: keyFieldName, Value: k}}, props...)
+	}
+	return nil
+}
+
+func loadProperties(v reflect.Value, k *datastore.Key, props []datastore.Property) error {
+	nv := reflect.New(v.Type().Elem())
+	if x, isOk := nv.Interface().(datastore.KeyLoader); isOk {
+		if err := x.LoadKey(k); err != nil {
+			return fmt.Errorf("goloquent: %v", err)
+		}
+	} else if k != nil {
+		props = append([]datastore.Property{{Name: keyFieldName, Value: k}}, props...)
+	}
+	if err := nv.Interface().(datastore.PropertyLoadSaver).Load(props); err != nil {
+		return fmt.Errorf("goloquent: %v", err)
+	}
+	v.Elem().Set(nv.Elem())
+	return nil
+}
+
+type overflowValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type overflowEntity struct {
+	Key        string                   `json:"key,omitempty"`
+	Properties map[string]overflowValue `json:"properties"`
+}
+
+func encodeOverflow(it interface{}) (overflowValue, error) {
+	var (
+		t   string
+		v   interface{}
+		err error
+	)
+	switch vi := it.(type) {
+	case nil:
+		return overflowValue{Type: "null"}, nil
+	case string:
+		t, v = "string", vi
+	case bool:
+		t, v = "bool", vi
+	case int, int8, int16, int32, int64:
+		t, v = "int", vi
+	case float32, float64:
+		t, v = "float", vi
+	case time.Time:
+		t, v = "time", vi.Format(time.RFC3339Nano)
+	case []byte:
+		t, v = "bytes", vi
+	case *datastore.Key:
+		if vi == nil {
+			return overflowValue{Type: "null"}, nil
+		}
+		t, v = "key", stringifyKey(vi)
+	case datastore.GeoPoint:
+		t, v = "geo", geoLocation{vi.Lat, vi.Lng}
+	case []interface{}:
+		arr := make([]overflowValue, len(vi))
+		for i, x := range vi {
+			arr[i], err = encodeOverflow(x)
+			if err != nil {
+				return overflowValue{}, err
+			}
+		}
+		t, v = "array", arr
+	case *datastore.Entity:
+		if vi == nil {
+			return overflowValue{Type: "null"}, nil
+		}
+		e := overflowEntity{Properties: make(map[string]overflowValue)}
+		if vi.Key != nil {
+			e.Key = stringifyKey(vi.Key)
+		}
+		for _, p := range vi.Properties {
+			e.Properties[p.Name], err = encodeOverflow(p.Value)
+			if err != nil {
+				return overflowValue{}, err
+			}
+		}
+		t, v = "entity", e
+	default:
+		return overflowValue{}, fmt.Errorf("goloquent: unsupported property data type %T", it)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return overflowValue{}, fmt.Errorf("goloquent: unable to marshal the value %v", it)
+	}
+	return overflowValue{Type: t, Value: b}, nil
+}
+
+func decodeOverflow(ov overflowValue) (interface{}, error) {
+	var err error
+	switch ov.Type {
+	case "null":
+		return nil, nil
+	case "string":
+		var s string
+		err = json.Unmarshal(ov.Value, &s)
+		return s, err
+	case "bool":
+		var b bool
+		err = json.Unmarshal(ov.Value, &b)
+		return b, err
+	case "int":
+		var n int64
+		err = json.Unmarshal(ov.Value, &n)
+		return n, err
+	case "float":
+		var f float64
+		err = json.Unmarshal(ov.Value, &f)
+		return f, err
+	case "time":
+		var s string
+		if err = json.Unmarshal(ov.Value, &s); err != nil {
+			return nil, err
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case "bytes":
+		var b []byte
+		err = json.Unmarshal(ov.Value, &b)
+		return b, err
+	case "key":
+		var s string
+		if err = json.Unmarshal(ov.Value, &s); err != nil {
+			return nil, err
+		}
+		return parseKey(s)
+	case "geo":
+		var g geoLocation
+		if err = json.Unmarshal(ov.Value, &g); err != nil {
+			return nil, err
+		}
+		return datastore.GeoPoint{Lat: g.Latitude, Lng: g.Longitude}, nil
+	case "array":
+		arr := make([]overflowValue, 0)
+		if err = json.Unmarshal(ov.Value, &arr); err != nil {
+			return nil, err
+		}
+		list := make([]interface{}, len(arr))
+		for i, x := range arr {
+			list[i], err = decodeOverflow(x)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case "entity":
+		var oe overflowEntity
+		if err = json.Unmarshal(ov.Value, &oe); err != nil {
+			return nil, err
+		}
+		e := new(datastore.Entity)
+		if oe.Key != "" {
+			e.Key, err = parseKey(oe.Key)
+			if err != nil {
+				return nil, err
+			}
+		}
+		e.Properties, err = decodeOverflowProperties(oe.Properties)
+		return e, err
+	}
+	return nil, fmt.Errorf("goloquent: invalid overflow data type %q", ov.Type)
+}
+
+func decodeOverflowProperties(m map[string]overflowValue) ([]datastore.Property, error) {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	props := make([]datastore.Property, 0, len(m))
+	for _, k := range names {
+		v, err := decodeOverflow(m[k])
+		if err != nil {
+			return nil, err
+		}
+		props = append(props, datastore.Property{Name: k, Value: v})
+	}
+	return props, nil
+}
+
+func marshalOverflow(props []datastore.Property) (interface{}, error) {
+	if len(props) <= 0 {
+		return nil, nil
+	}
+	m := make(map[string]overflowValue)
+	for _, p := range props {
+		v, err := encodeOverflow(p.Value)
+		if err != nil {
+			return nil, err
+		}
+		m[p.Name] = v
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("goloquent: unable to marshal overflow properties")
+	}
+	return b2s(b), nil
+}
+
+func unmarshalOverflow(b []byte) ([]datastore.Property, error) {
+	if len(b) <= 0 {
+		return nil, nil
+	}
+	m := make(map[string]overflowValue)
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("goloquent: unable to unmarshal overflow properties, %v", err)
+	}
+	return decodeOverflowProperties(m)
+}
+
+// columnToValue will decode the column value base on the database type name
+func columnToValue(dbType string, b []byte) (interface{}, error) {
+	if b == nil {
+		return nil, nil
+	}
+	str := string(b)
+	t := strings.ToUpper(dbType)
+	switch {
+	case strings.Contains(t, "BOOL"):
+		return strconv.ParseBool(str)
+	case strings.Contains(t, "INT") && !strings.Contains(t, "POINT") && !strings.Contains(t, "INTERVAL"):
+		return strconv.ParseInt(str, 10, 64)
+	case strings.Contains(t, "DECIMAL"), strings.Contains(t, "NUMERIC"):
+		return ParseDecimal(str)
+	case strings.Contains(t, "FLOAT"), strings.Contains(t, "DOUBLE"), t == "REAL":
+		return strconv.ParseFloat(str, 64)
+	case strings.Contains(t, "TIMESTAMP"), t == "DATETIME", t == "DATE":
+		if dt, err := parseTime(str); err == nil {
+			return dt, nil
+		}
+		if dt, err := time.Parse("2006-01-02", str); err == nil {
+			return dt, nil
+		}
+		return nil, fmt.Errorf("goloquent: unable to parse %q to date time", str)
+	case strings.Contains(t, "JSON"):
+		var it interface{}
+		if err := json.Unmarshal(b, &it); err != nil {
+			return nil, fmt.Errorf("goloquent: %v", err)
+		}
+		return it, nil
+	case strings.Contains(t, "BLOB"), strings.Contains(t, "BINARY"), t == "BYTEA":
+		return b, nil
+	}
+	return str, nil
+}
+
+func (it *Iterator) loadDynamic(src interface{}) error {
+	k, err := parseKey(string(it.Get(keyFieldName)))
+	if err != nil {
+		return err
+	}
+	props := make([]datastore.Property, 0, len(it.columns))
+	for _, c := range it.columns {
+		if isJSONPathColumn(c) {
+			continue
+		}
+		switch c {
+		case pkColumn, parentColumn, softDeleteColumn:
+			continue
+		case overflowColumn:
+			list, err := unmarshalOverflow(it.Get(c))
+			if err != nil {
+				return err
+			}
+			props = append(props, list...)
+			continue
+		}
+		v, err := columnToValue(it.types[c], it.Get(c))
+		if err != nil {
+			return err
+		}
+		props = append(props, datastore.Property{Name: c, Value: v})
+	}
+	return loadProperties(reflect.ValueOf(src), k, props)
+}
+
+func (b *builder) resolveDynamic(e *entity) error {
+	if !e.isDynamic || e.tableColumns != nil {
+		return nil
+	}
+	if e.typeOf == typeOfPropertyList && e.name == typeOfPropertyList.Name() {
+		return fmt.Errorf("goloquent: missing table name for datastore.PropertyList")
+	}
+	e.tableColumns = make([]string, 0)
+	for _, c := range b.db.dialect.GetColumns(e.Name()) {
+		if !isJSONPathColumn(c) {
+			e.tableColumns = append(e.tableColumns, c)
+		}
+	}
+	if x, isOk := b.db.dialect.(boolColumner); isOk {
+		e.boolColumns = x.boolColumns(e.Name())
+	}
+	for _, c := range e.tableColumns {
+		if c == softDeleteColumn {
+			e.fields[softDeleteColumn] = Column{
+				names: []string{softDeleteColumn},
+				field: newField(tag{name: softDeleteColumn}, nil, nil, nil, typeOfSoftDelete, true, nil),
+			}
+		}
+	}
+	return nil
+}
+
+// boolColumner is the dialect which is unable to differentiate the bool column from the type name of driver,
+// eg. mysql is storing bool as `tinyint(1)` and the type name is `TINYINT`
+type boolColumner interface {
+	boolColumns(table string) []string
+}
+
+// setTypes will override the type name of the bool columns, so the column is decoded as bool
+func (e *entity) setTypes(it *Iterator) {
+	for _, c := range e.boolColumns {
+		if _, isOk := it.types[c]; isOk {
+			it.types[c] = "BOOL"
+		}
+	}
+}
+
+// dynamicRow will map the properties to table columns, the unknown properties will go to overflow column,
+// the column which is absent from the properties is set to null, so the entity is replaced as a whole
+func (b *builder) dynamicRow(e *entity, k *datastore.Key, props []datastore.Property) (map[string]interface{}, error) {
+	cols := newDictionary(e.tableColumns)
+	row := make(map[string]interface{})
+	overflow := make([]datastore.Property, 0)
+	for _, p := range props {
+		if isReserveFieldName(p.Name) {
+			continue
+		}
+		if !cols.has(p.Name) {
+			overflow = append(overflow, p)
+			continue
+		}
+		v, err := normalizeValue(p.Value)
+		if err != nil {
+			return nil, err
+		}
+		vv, err := b.propertyValue(Property{name: []string{p.Name}, typeOf: reflect.TypeOf(p.Value), Value: v})
+		if err != nil {
+			return nil, err
+		}
+		row[p.Name] = vv
+	}
+	for _, c := range e.tableColumns {
+		if _, isOk := row[c]; !isOk && !isReserveFieldName(c) {
+			row[c] = nil
+		}
+	}
+	vv, err := marshalOverflow(overflow)
+	if err != nil {
+		return nil, err
+	}
+	row[pkColumn] = stringPk(k)
+	row[overflowColumn] = vv
+	row[parentColumn], _ = interfaceToValue(k.Parent)
+	return row, nil
+}
+
+func (b *builder) putDynamicStmt(parentKey []*datastore.Key, e *entity) (*stmt, []string, error) {
+	if err := b.resolveDynamic(e); err != nil {
+		return nil, nil, err
+	}
+	v := e.slice.Elem()
+	rows := make([]map[string]interface{}, 0, v.Len())
+	dict := make(map[string]bool)
+	for i := 0; i < v.Len(); i++ {
+		f := reflect.Indirect(v.Index(i))
+		if !f.IsValid() {
+			return nil, nil, fmt.Errorf("goloquent: invalid value entity value %v", f)
+		}
+		vi := reflect.New(f.Type())
+		vi.Elem().Set(f)
+		props, err := saveProperties(vi)
+		if err != nil {
+			return nil, nil, err
+		}
+		k, props := splitKeyProperty(props)
+		var pk *datastore.Key
+		if len(parentKey) > 0 {
+			pk = newPrimaryKey(e.Name(), parentKey[0])
+		} else {
+			pk = newPrimaryKey(e.Name(), k)
+		}
+		if err := setDynamicKey(vi, pk); err != nil {
+			return nil, nil, err
+		}
+		f.Set(vi.Elem())
+		row, err := b.dynamicRow(e, pk, props)
+		if err != nil {
+			return nil, nil, err
+		}
+		for c := range row {
+			dict[c] = true
+		}
+		rows = append(rows, row)
+	}
+
+	cols := []string{pkColumn, parentColumn}
+	for _, c := range e.tableColumns {
+		if dict[c] && c != pkColumn && c != parentColumn && c != overflowColumn {
+			cols = append(cols, c)
+		}
+	}
+	cols = append(cols, overflowColumn)
+
+	buf, args := new(bytes.Buffer), make([]interface{}, 0)
+	buf.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES ",
+		b.db.dialect.GetTable(e.Name()),
+		b.db.dialect.Quote(strings.Join(cols, b.db.dialect.Quote(",")))))
+	for i, row := range rows {
+		if i != 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("(")
+		for j, c := range cols {
+			if j != 0 {
+				buf.WriteString(",")
+			}
+			vv, isOk := row[c]
+			if !isOk {
+				buf.WriteString("DEFAULT")
+				continue
+			}
+			buf.WriteString(variable)
+			args = append(args, vv)
+		}
+		buf.WriteString(")")
+	}
+	buf.WriteString(";")
+	return &stmt{
+		statement: buf,
+		arguments: args,
+	}, cols, nil
+}
+
+func (b *builder) saveDynamicMutation(e *entity) (*stmt, error) {
+	if err := b.resolveDynamic(e); err != nil {
+		return nil, err
+	}
+	f := e.slice.Elem().Index(0)
+	props, err := saveProperties(f)
+	if err != nil {
+		return nil, err
+	}
+	k, props := splitKeyProperty(props)
+	if k == nil || k.Incomplete() {
+		return nil, fmt.Errorf("goloquent: invalid key value, %v", k)
+	}
+	row, err := b.dynamicRow(e, k, props)
+	if err != nil {
+		return nil, err
+	}
+	delete(row, pkColumn)
+	delete(row, parentColumn)
+
+	cols := make([]string, 0, len(row))
+	omits := newDictionary(b.query.omits)
+	for c := range row {
+		if !omits.has(c) {
+			cols = append(cols, c)
+		}
+	}
+	sort.Strings(cols)
+
+	buf, args := new(bytes.Buffer), make([]interface{}, 0)
+	buf.WriteString(fmt.Sprintf("UPDATE %s SET ", b.db.dialect.GetTable(e.Name())))
+	for _, c := range cols {
+		buf.WriteString(fmt.Sprintf("%s = %s,", b.db.dialect.Quote(c), variable))
+		args = append(args, row[c])
+	}
+	buf.Truncate(buf.Len() - 1)
+	buf.WriteString(fmt.Sprintf(" WHERE %s = %s;", b.db.dialect.Quote(pkColumn), variable))
+	args = append(args, stringPk(k))
+	return &stmt{
+		statement: buf,
+		arguments: args,
+	}, nil
+}
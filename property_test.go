@@ -0,0 +1,160 @@
+package goloquent
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+func TestDynamicEntity(t *testing.T) {
+	e, err := newEntity(new(datastore.PropertyList))
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if !e.isDynamic {
+		t.Fatal(`"datastore.PropertyList" should be dynamic entity`)
+	}
+	if !reflect.DeepEqual(e.Columns(), []string{pkColumn, parentColumn, overflowColumn}) {
+		t.Fatalf(errUnexpectedResult, "Columns")
+	}
+
+	e, err = newEntity(&[]datastore.PropertyList{})
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if !e.isDynamic {
+		t.Fatal(`"[]datastore.PropertyList" should be dynamic entity`)
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	dt := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	parent := datastore.NameKey("Parent", "a", nil)
+	props := []datastore.Property{
+		{Name: "Bool", Value: true},
+		{Name: "Bytes", Value: []byte("hello")},
+		{Name: "Entity", Value: &datastore.Entity{
+			Key:        datastore.IDKey("Child", 10, parent),
+			Properties: []datastore.Property{{Name: "Name", Value: "child"}},
+		}},
+		{Name: "Float", Value: float64(10.5)},
+		{Name: "Geo", Value: datastore.GeoPoint{Lat: 3.1, Lng: 101.6}},
+		{Name: "Int", Value: int64(100)},
+		{Name: "Key", Value: parent},
+		{Name: "List", Value: []interface{}{"a", int64(1)}},
+		{Name: "Null", Value: nil},
+		{Name: "String", Value: "text"},
+		{Name: "Time", Value: dt},
+	}
+
+	v, err := marshalOverflow(props)
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	result, err := unmarshalOverflow([]byte(v.(string)))
+	if err != nil {
+		t.Fatalf("Unexpected err, %v", err)
+	}
+	if len(result) != len(props) {
+		t.Fatalf(errUnexpectedResult, "unmarshalOverflow")
+	}
+	for i, p := range props {
+		if p.Name != result[i].Name {
+			t.Fatalf(errUnexpectedResult, p.Name)
+		}
+		switch vi := p.Value.(type) {
+		case *datastore.Key:
+			if !vi.Equal(result[i].Value.(*datastore.Key)) {
+				t.Fatalf(errUnexpectedResult, p.Name)
+			}
+		case *datastore.Entity:
+			e := result[i].Value.(*datastore.Entity)
+			if !vi.Key.Equal(e.Key) || !reflect.DeepEqual(vi.Properties, e.Properties) {
+				t.Fatalf(errUnexpectedResult, p.Name)
+			}
+		default:
+			if !reflect.DeepEqual(p.Value, result[i].Value) {
+				t.Fatalf(errUnexpectedResult, p.Name)
+			}
+		}
+	}
+}
+
+func TestColumnToValue(t *testing.T) {
+	checks := []struct {
+		dbType string
+		value  []byte
+		result interface{}
+	}{
+		{"BIGINT", []byte("10"), int64(10)},
+		{"INT4", []byte("-10"), int64(-10)},
+		{"DOUBLE", []byte("1.5"), float64(1.5)},
+		{"DECIMAL", []byte("12.50"), MustDecimal("12.50")},
+		{"NUMERIC", []byte("-0.1"), MustDecimal("-0.1")},
+		{"BOOL", []byte("true"), true},
+		{"VARCHAR", []byte("text"), "text"},
+		{"DATETIME", []byte("2019-01-02 03:04:05"), time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"VARCHAR", nil, nil},
+	}
+	for _, c := range checks {
+		v, err := columnToValue(c.dbType, c.value)
+		if err != nil {
+			t.Fatalf("Unexpected err, %v", err)
+		}
+		if !reflect.DeepEqual(v, c.result) {
+			t.Fatalf(errUnexpectedResult, c.dbType)
+		}
+	}
+
+	// `tinyint(1)` of mysql is decoded as bool
+	e := &entity{boolColumns: []string{"Active"}}
+	it := &Iterator{types: map[string]string{"Active": "TINYINT", "Level": "TINYINT"}}
+	e.setTypes(it)
+	if v, _ := columnToValue(it.types["Active"], []byte("1")); v != true {
+		t.Fatalf(errUnexpectedResult, "setTypes")
+	}
+	if v, _ := columnToValue(it.types["Level"], []byte("1")); v != int64(1) {
+		t.Fatalf(errUnexpectedResult, "setTypes")
+	}
+}
+
+func TestDynamicRow(t *testing.T) {
+	db := newFakeDB(t)
+	b := newBuilder(db.NewQuery())
+	k := datastore.NameKey("Profile", "p1", nil)
+	list := []*datastore.PropertyList{{
+		{Name: keyFieldName, Value: k},
+		{Name: "Name", Value: "Sam"},
+	}}
+	e, err := newEntity(&list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.setName("Profile")
+	e.tableColumns = []string{pkColumn, parentColumn, "Name", "Age", softDeleteColumn, overflowColumn}
+
+	// the dropped property is set to null, the reserved columns are untouched
+	s, err := b.saveDynamicMutation(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.string() != "UPDATE ``.`Profile` SET `$Overflow` = ??,`Age` = ??,`Name` = ?? WHERE `$Key` = ??;" {
+		t.Fatalf("unexpected statement %s", s.string())
+	}
+	if args := s.arguments; args[1] != nil || args[2] != "Sam" {
+		t.Fatalf("unexpected arguments %v", args)
+	}
+
+	s, cols, err := b.putDynamicStmt(nil, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cols, []string{pkColumn, parentColumn, "Name", "Age", overflowColumn}) {
+		t.Fatalf("unexpected columns %v", cols)
+	}
+	if s.arguments[3] != nil {
+		t.Fatalf("unexpected arguments %v", s.arguments)
+	}
+}
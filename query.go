@@ -5,8 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"strconv"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/datastore"
 	"github.com/si3nloong/goloquent/expr"
@@ -34,6 +34,11 @@ const (
 	IsArray
 	IsType
 	MatchAgainst
+	Near
+	WithinBox
+	Contains
+	Overlaps
+	ContainedBy
 )
 
 type sortDirection int
@@ -67,6 +72,9 @@ func checkSinglePtr(it interface{}) error {
 		return fmt.Errorf("goloquent: entity must be addressable")
 	}
 	v = v.Elem()
+	if isDynamicType(v.Type()) {
+		return nil
+	}
 	if v.Kind() != reflect.Struct || isBaseType(v.Type()) {
 		return fmt.Errorf("goloquent: entity data type must be struct")
 	}
@@ -76,10 +84,13 @@ func checkSinglePtr(it interface{}) error {
 type group struct {
 	isGroup bool
 	data    []interface{}
+	depth   int
 }
 
 type scope struct {
 	table      string
+	alias      string
+	joins      []join
 	distinctOn []string
 	projection []string
 	omits      []string
@@ -91,6 +102,7 @@ type scope struct {
 	errs       []error
 	noScope    bool
 	lockMode   locked
+	cacheTTL   time.Duration
 }
 
 // Query :
@@ -135,6 +147,14 @@ func (q *Query) getError() error {
 	return nil
 }
 
+// checkJoin will return error when the operation is not supporting join, the join is silently ignored otherwise
+func (q *Query) checkJoin(op string) error {
+	if len(q.joins) > 0 {
+		return fmt.Errorf("goloquent: join is not supported by %q", op)
+	}
+	return nil
+}
+
 // Select :
 func (q *Query) Select(fields ...string) *Query {
 	q = q.clone()
@@ -198,6 +218,9 @@ func (q *Query) Find(key *datastore.Key, model interface{}) error {
 	if err := q.getError(); err != nil {
 		return err
 	}
+	if err := q.checkJoin("Find"); err != nil {
+		return err
+	}
 	if err := checkSinglePtr(model); err != nil {
 		return err
 	}
@@ -205,7 +228,31 @@ func (q *Query) Find(key *datastore.Key, model interface{}) error {
 		return fmt.Errorf("goloquent: find action with invalid key value, %q", key)
 	}
 	q = q.Where(keyFieldName, "=", key).Limit(1)
-	return newBuilder(q).get(model, true)
+	if q.table == "" && reflect.TypeOf(model).Elem() == typeOfPropertyList {
+		q.table = key.Kind
+	}
+	b := newBuilder(q)
+	if b.useEntityCache(reflect.TypeOf(model).Elem()) {
+		return b.findEntity(key, model)
+	}
+	return b.get(model, true)
+}
+
+// GetMulti : retrieve the entities by keys, the result is following the order of the keys.
+// It return `MultiError` with `ErrNoSuchEntity` at the index of the missing entity
+func (q *Query) GetMulti(keys []*datastore.Key, model interface{}) error {
+	q = q.clone()
+	if err := q.getError(); err != nil {
+		return err
+	}
+	if err := q.checkJoin("GetMulti"); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("goloquent: model must be a pointer of slice")
+	}
+	return newBuilder(q).getByKeys(keys, model)
 }
 
 // First :
@@ -214,6 +261,10 @@ func (q *Query) First(model interface{}) error {
 	if err := q.getError(); err != nil {
 		return err
 	}
+	if len(q.joins) > 0 {
+		q.Limit(1)
+		return newBuilder(q).getJoin(model, false)
+	}
 	if err := checkSinglePtr(model); err != nil {
 		return err
 	}
@@ -227,20 +278,60 @@ func (q *Query) Get(model interface{}) error {
 	if err := q.getError(); err != nil {
 		return err
 	}
+	if len(q.joins) > 0 {
+		return newBuilder(q).getJoin(model, false)
+	}
 	return newBuilder(q).getMulti(model)
 }
 
+// Keys : retrieve the primary keys only, it will not decode any other column
+func (q *Query) Keys() ([]*datastore.Key, error) {
+	q = q.clone()
+	if err := q.getError(); err != nil {
+		return nil, err
+	}
+	if err := q.checkJoin("Keys"); err != nil {
+		return nil, err
+	}
+	return newBuilder(q).getKeys()
+}
+
+// KeysOnly : return an iterator which only select the primary key,
+// use `Iterator.Key` to retrieve the key of each record
+func (q *Query) KeysOnly() (*Iterator, error) {
+	q = q.clone()
+	if err := q.getError(); err != nil {
+		return nil, err
+	}
+	if err := q.checkJoin("KeysOnly"); err != nil {
+		return nil, err
+	}
+	return newBuilder(q).keysOnly()
+}
+
 // Paginate :
 func (q *Query) Paginate(p *Pagination, model interface{}) error {
 	if err := q.getError(); err != nil {
 		return err
 	}
+	if err := q.checkJoin("Paginate"); err != nil {
+		return err
+	}
+	q, err := q.paginateQuery(p)
+	if err != nil {
+		return err
+	}
+	return newBuilder(q).paginate(p, model)
+}
+
+// paginateQuery will return the query of the page, it fetch one more record to determine the next cursor
+func (q *Query) paginateQuery(p *Pagination) (*Query, error) {
 	q = q.clone()
 	if p.query != nil {
 		q = q.append(p.query)
 	}
 	if p.Limit > maxLimit {
-		return fmt.Errorf("goloquent: limit overflow : %d, maximum limit : %d", p.Limit, maxLimit)
+		return nil, fmt.Errorf("goloquent: limit overflow : %d, maximum limit : %d", p.Limit, maxLimit)
 	} else if p.Limit <= 0 {
 		p.Limit = defaultLimit
 	}
@@ -258,7 +349,46 @@ func (q *Query) Paginate(p *Pagination, model interface{}) error {
 	} else {
 		q = q.OrderBy(pkColumn)
 	}
-	return newBuilder(q).paginate(p, model)
+	return q, nil
+}
+
+// As : set the alias of the query table, eg. `As("u")`
+func (q *Query) As(alias string) *Query {
+	q.alias = strings.TrimSpace(alias)
+	return q
+}
+
+func (q *Query) join(kind, table, left, op, right string) *Query {
+	q = q.clone()
+	name, alias, err := parseTableAlias(table)
+	if err != nil {
+		q.errs = append(q.errs, err)
+		return q
+	}
+	optr, err := joinOperator(op)
+	if err != nil {
+		q.errs = append(q.errs, err)
+		return q
+	}
+	q.joins = append(q.joins, join{
+		kind:  kind,
+		table: name,
+		alias: alias,
+		left:  strings.TrimSpace(left),
+		op:    optr,
+		right: strings.TrimSpace(right),
+	})
+	return q
+}
+
+// Join : inner join with the table, the table may have alias, eg. `Join("Merchant AS m", "u.MerchantID", "=", "m.__key__")`
+func (q *Query) Join(table, leftField, op, rightField string) *Query {
+	return q.join("INNER JOIN", table, leftField, op, rightField)
+}
+
+// LeftJoin : left join with the table, the unmatched model will remain zero value (or nil)
+func (q *Query) LeftJoin(table, leftField, op, rightField string) *Query {
+	return q.join("LEFT JOIN", table, leftField, op, rightField)
 }
 
 // Ancestor :
@@ -272,7 +402,7 @@ func (q *Query) Ancestor(ancestor *datastore.Key) *Query {
 		return q
 	}
 	q = q.clone()
-	q.ancestors = append(q.ancestors, group{false, []interface{}{ancestor}})
+	q.ancestors = append(q.ancestors, group{false, []interface{}{ancestor}, 0})
 	return q
 }
 
@@ -282,7 +412,7 @@ func (q *Query) AnyOfAncestor(ancestors ...*datastore.Key) *Query {
 		q.errs = append(q.errs, errors.New(`goloquent: "AnyOfAncestor" cannot be empty`))
 		return q
 	}
-	g := group{true, make([]interface{}, 0)}
+	g := group{true, make([]interface{}, 0), 0}
 	for _, a := range ancestors {
 		if a == nil {
 			q.errs = append(q.errs, errors.New("goloquent: ancestor key cannot be nil"))
@@ -299,6 +429,22 @@ func (q *Query) AnyOfAncestor(ancestors ...*datastore.Key) *Query {
 	return q
 }
 
+// Descendants : filter the entities which is descendant of the key,
+// depth limit the levels below the key, zero or negative means unlimited
+func (q *Query) Descendants(key *datastore.Key, depth int) *Query {
+	if key == nil {
+		q.errs = append(q.errs, errors.New("goloquent: descendant key cannot be nil"))
+		return q
+	}
+	if key.Incomplete() {
+		q.errs = append(q.errs, fmt.Errorf("goloquent: descendant key is incomplete, %v", key))
+		return q
+	}
+	q = q.clone()
+	q.ancestors = append(q.ancestors, group{false, []interface{}{key}, depth})
+	return q
+}
+
 func (q *Query) where(field, op string, value interface{}, isJSON bool) *Query {
 	op = strings.TrimSpace(strings.ToLower(op))
 	var optr operator
@@ -414,6 +560,69 @@ func (q *Query) WhereNotIn(field string, v interface{}) *Query {
 	return q.Where(field, "nin", v)
 }
 
+// WhereNear : filter the geo point field which is within the distance (in meters) of the point
+func (q *Query) WhereNear(field string, point datastore.GeoPoint, meters float64) *Query {
+	if !point.Valid() {
+		q.errs = append(q.errs, fmt.Errorf("goloquent: invalid geo point %v", point))
+		return q
+	}
+	if meters < 0 {
+		q.errs = append(q.errs, fmt.Errorf("goloquent: distance cannot be negative, %v", meters))
+		return q
+	}
+	q = q.clone()
+	q.filters = append(q.filters, Filter{
+		field:    field,
+		operator: Near,
+		value:    geoNear{point, meters},
+	})
+	return q
+}
+
+// WhereWithinBox : filter the geo point field which is inside the box of south west and north east point
+func (q *Query) WhereWithinBox(field string, sw, ne datastore.GeoPoint) *Query {
+	if !sw.Valid() || !ne.Valid() {
+		q.errs = append(q.errs, fmt.Errorf("goloquent: invalid geo box %v, %v", sw, ne))
+		return q
+	}
+	q = q.clone()
+	q.filters = append(q.filters, Filter{
+		field:    field,
+		operator: WithinBox,
+		value:    geoBox{sw, ne},
+	})
+	return q
+}
+
+// WhereContains : filter the array field which contains all the values, the single value is treated as array of one element
+func (q *Query) WhereContains(field string, v interface{}) *Query {
+	return q.whereArray(field, Contains, v)
+}
+
+// WhereOverlaps : filter the array field which contains any of the values
+func (q *Query) WhereOverlaps(field string, v interface{}) *Query {
+	return q.whereArray(field, Overlaps, v)
+}
+
+// WhereContainedBy : filter the array field which all the elements are within the values
+func (q *Query) WhereContainedBy(field string, v interface{}) *Query {
+	return q.whereArray(field, ContainedBy, v)
+}
+
+func (q *Query) whereArray(field string, op operator, v interface{}) *Query {
+	if v == nil {
+		q.errs = append(q.errs, fmt.Errorf("goloquent: value of array filter cannot be nil"))
+		return q
+	}
+	q = q.clone()
+	q.filters = append(q.filters, Filter{
+		field:    field,
+		operator: op,
+		value:    v,
+	})
+	return q
+}
+
 // WhereLike :
 func (q *Query) WhereLike(field, v string) *Query {
 	return q.Where(field, "like", v)
@@ -482,36 +691,37 @@ func (q *Query) WhereJSONIsArray(field string) *Query {
 
 // MatchAgainst :
 func (q *Query) MatchAgainst(fields []string, values ...string) *Query {
-	f := Filter{}
-	f.operator = MatchAgainst
-	buf := new(bytes.Buffer)
-	buf.WriteString("MATCH(")
-
-	for i, field := range fields {
-		if i > 0 {
-			buf.WriteByte(',')
-		}
-		buf.WriteString("`" + field + "`")
-	}
-	buf.WriteString(") AGAINST(")
-	v := ""
-	for i, _ := range values {
-		if i > 0 {
-			buf.WriteByte(' ')
-		}
+	return q.match(fields, strings.Join(values, " "), NaturalLanguageMode, false)
+}
 
-		// buf.WriteString(strconv.Quote(val))
-		values[i] = strconv.Quote(values[i])
-		if i != len(values)-1 {
-			v += strconv.Quote(values[i]) + ","
-		} else {
-			v += strconv.Quote(values[i])
-		}
+// Match : full text search on the fields, language is the text search configuration of postgres,
+// the fields are matched individually when every field is tagged with `fulltext`, otherwise it's same as `MatchAgainst`
+func (q *Query) Match(fields []string, query string, mode SearchMode, language ...string) *Query {
+	return q.match(fields, query, mode, true, language...)
+}
+
+func (q *Query) match(fields []string, query string, mode SearchMode, perField bool, language ...string) *Query {
+	if len(fields) <= 0 {
+		q.errs = append(q.errs, fmt.Errorf("goloquent: missing fields for full text search"))
+		return q
+	}
+	cfg, err := textSearchConfig(language...)
+	if err != nil {
+		q.errs = append(q.errs, err)
+		return q
 	}
-	buf.WriteString("?)")
-	f.raw = buf.String()
-	f.value = v
-	q.filters = append(q.filters, f)
+	q = q.clone()
+	q.filters = append(q.filters, Filter{
+		operator: MatchAgainst,
+		value:    fullTextSearch{fields, query, mode, cfg, perField},
+	})
+	return q
+}
+
+// Cache : cache the query result with the ttl, the cache is invalidated when the table is mutated through the same `DB`,
+// the write of `RawQuery` and the statement of `DB.Exec` which the table is unable to resolve will bypass the invalidation
+func (q *Query) Cache(ttl time.Duration) *Query {
+	q.cacheTTL = ttl
 	return q
 }
 
@@ -586,11 +796,23 @@ func (q *Query) Offset(offset int) *Query {
 
 // ReplaceInto :
 func (q *Query) ReplaceInto(table string) error {
+	if err := q.getError(); err != nil {
+		return err
+	}
+	if err := q.checkJoin("ReplaceInto"); err != nil {
+		return err
+	}
 	return newBuilder(q).replaceInto(table)
 }
 
 // InsertInto :
 func (q *Query) InsertInto(table string) error {
+	if err := q.getError(); err != nil {
+		return err
+	}
+	if err := q.checkJoin("InsertInto"); err != nil {
+		return err
+	}
 	return newBuilder(q).insertInto(table)
 }
 
@@ -599,6 +821,9 @@ func (q *Query) Update(v interface{}) error {
 	if err := q.getError(); err != nil {
 		return err
 	}
+	if err := q.checkJoin("Update"); err != nil {
+		return err
+	}
 	// q = q.OrderBy(pkColumn)
 	return newBuilder(q).updateMulti(v)
 }
@@ -608,6 +833,9 @@ func (q *Query) Flush() error {
 	if err := q.getError(); err != nil {
 		return err
 	}
+	if err := q.checkJoin("Flush"); err != nil {
+		return err
+	}
 	if q.table == "" {
 		return fmt.Errorf("goloquent: unable to perform delete without table name")
 	}
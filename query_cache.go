@@ -0,0 +1,270 @@
+package goloquent
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/base64"
+	"encoding/gob"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache : the storage of query result cache, the value is encoded result set
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	// Set : the value never expire if ttl is zero
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiredAt time.Time
+}
+
+// LRUCache : the in-memory `Cache` which evict the least recently used entry
+type LRUCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+var _ Cache = (*LRUCache)(nil)
+
+// NewLRUCache : create in-memory cache with size limit
+func NewLRUCache(size int) *LRUCache {
+	if size <= 0 {
+		size = 1000
+	}
+	return &LRUCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get :
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, isOk := c.items[key]
+	if !isOk {
+		return nil, false
+	}
+	entry := e.Value.(*lruEntry)
+	if !entry.expiredAt.IsZero() && time.Now().After(entry.expiredAt) {
+		c.ll.Remove(e)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return entry.value, true
+}
+
+// Set :
+func (c *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &lruEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiredAt = time.Now().Add(ttl)
+	}
+	if e, isOk := c.items[key]; isOk {
+		e.Value = entry
+		c.ll.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.ll.PushFront(entry)
+	for c.ll.Len() > c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*lruEntry).key)
+	}
+}
+
+// Delete :
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, isOk := c.items[key]; isOk {
+		c.ll.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+// Len : number of cached entries
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// cachedResult is the result set which stored in the cache
+type cachedResult struct {
+	Type    string
+	Columns []string
+	Types   map[string]string
+	Rows    [][][]byte
+	Nulls   [][]bool
+}
+
+// tableSet is the tables which mutated in transaction
+type tableSet struct {
+	mu     sync.Mutex
+	tables map[string]bool
+}
+
+func (s *tableSet) add(table string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables[table] = true
+}
+
+func (s *tableSet) list() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tables := make([]string, 0, len(s.tables))
+	for t := range s.tables {
+		tables = append(tables, t)
+	}
+	return tables
+}
+
+func (db *DB) inTransaction() bool {
+	_, isOk := db.client.sqlCommon.(*sql.Tx)
+	return isOk
+}
+
+// tableVersionKey is the cache key of table version, every mutation of the table will change the version,
+// so the result cache of the table is invalidated even the cache is shared by multiple processes
+func (db *DB) tableVersionKey(table string) string {
+	return fmt.Sprintf("goloquent:%s:%s:%s", db.driver, db.name, table)
+}
+
+func newTableVersion() []byte {
+	return []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+}
+
+// tableVersion will return the version of table from the cache, the version key is evictable,
+// so the missing version is minted as a new version instead of empty, otherwise the results
+// which cached before the version key is set will be returned again after it's evicted
+func tableVersion(c Cache, key string) []byte {
+	if v, isOk := c.Get(key); isOk && len(v) > 0 {
+		return v
+	}
+	v := newTableVersion()
+	c.Set(key, v, 0)
+	return v
+}
+
+// invalidate the query result cache of the tables
+func (db *DB) invalidate(tables ...string) {
+	version := newTableVersion()
+	for _, t := range tables {
+		if t == "" {
+			continue
+		}
+		// the mutation is not visible to others until commit, it will invalidate again after commit
+		if db.txWrites != nil {
+			db.txWrites.add(t)
+		}
+		if db.cache != nil {
+			db.cache.Set(db.tableVersionKey(t), version, 0)
+		}
+	}
+}
+
+// resultCacheKey is the fingerprint of statement, arguments and the version of the tables
+func (db *DB) resultCacheKey(s *Stmt, tables []string) string {
+	h := sha1.New()
+	h.Write([]byte(s.Raw()))
+	for _, arg := range s.arguments {
+		h.Write([]byte(fmt.Sprintf("\x00%T:%v", arg, arg)))
+	}
+	for _, t := range tables {
+		h.Write([]byte("\x00" + t + ":"))
+		h.Write(tableVersion(db.cache, db.tableVersionKey(t)))
+	}
+	return "goloquent:result:" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func encodeResult(it *Iterator, typeName string) ([]byte, error) {
+	r := cachedResult{
+		Type:    typeName,
+		Columns: it.columns,
+		Types:   it.types,
+		Rows:    make([][][]byte, len(it.results)),
+		Nulls:   make([][]bool, len(it.results)),
+	}
+	for i, l := range it.results {
+		r.Rows[i] = make([][]byte, len(it.columns))
+		r.Nulls[i] = make([]bool, len(it.columns))
+		for j, c := range it.columns {
+			r.Rows[i][j] = l[c]
+			r.Nulls[i][j] = l[c] == nil
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeResult(b []byte, it *Iterator) (string, error) {
+	r := cachedResult{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&r); err != nil {
+		return "", err
+	}
+	it.columns = r.Columns
+	it.types = r.Types
+	for i, row := range r.Rows {
+		for j, c := range r.Columns {
+			v := row[j]
+			if r.Nulls[i][j] {
+				v = nil
+			} else if v == nil {
+				v = []byte{}
+			}
+			it.put(i, c, v)
+		}
+		it.patchKey()
+	}
+	return r.Type, nil
+}
+
+// queryTables will return the tables which involved in the query
+func queryTables(query scope) []string {
+	tables := []string{query.table}
+	for _, j := range query.joins {
+		tables = append(tables, j.table)
+	}
+	for _, f := range query.filters {
+		if q, isOk := f.value.(*Query); isOk {
+			tables = append(tables, queryTables(q.scope)...)
+		}
+	}
+	return tables
+}
+
+var writeTableRgx = regexp.MustCompile("(?i)^\\s*(?:INSERT\\s+(?:IGNORE\\s+)?INTO|REPLACE\\s+INTO|UPDATE|DELETE\\s+FROM|" +
+	"TRUNCATE(?:\\s+TABLE)?|ALTER\\s+TABLE|DROP\\s+TABLE(?:\\s+IF\\s+EXISTS)?)\\s+([`\"\\w.]+)")
+
+// writeTable will return the table which mutated by the hand-written statement, eg. `UPDATE User SET ...`,
+// it's empty if the statement is not a write or the table is unable to resolve, eg. `WITH ... UPDATE`
+func writeTable(query string) string {
+	result := writeTableRgx.FindStringSubmatch(query)
+	if len(result) < 2 {
+		return ""
+	}
+	paths := strings.Split(result[1], ".")
+	return strings.Trim(paths[len(paths)-1], "`\"")
+}
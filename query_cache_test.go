@@ -0,0 +1,141 @@
+package goloquent
+
+import (
+	"bytes"
+	"database/sql"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("a", []byte("1"), 0)
+	c.Set("b", []byte("2"), 0)
+	c.Get("a")
+	c.Set("c", []byte("3"), 0)
+	if _, isOk := c.Get("b"); isOk || c.Len() != 2 {
+		t.Fatal("least recently used entry should be evicted")
+	}
+	if v, isOk := c.Get("a"); !isOk || string(v) != "1" {
+		t.Fatalf(errUnexpectedResult, "LRUCache.Get")
+	}
+
+	c.Set("d", []byte("4"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, isOk := c.Get("d"); isOk {
+		t.Fatal("expired entry shouldn't be returned")
+	}
+	c.Delete("a")
+	if _, isOk := c.Get("a"); isOk {
+		t.Fatalf(errUnexpectedResult, "LRUCache.Delete")
+	}
+}
+
+func TestEncodeResult(t *testing.T) {
+	it := &Iterator{table: "User", position: -1, columns: []string{pkColumn, "Name", "Email"}}
+	it.put(0, pkColumn, []byte("'u1'"))
+	it.put(0, "Name", []byte(""))
+	it.put(0, "Email", nil)
+	it.patchKey()
+
+	b, err := encodeResult(it, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nit := &Iterator{table: "User", position: -1}
+	if _, err := decodeResult(b, nit); err != nil {
+		t.Fatal(err)
+	}
+	it.First()
+	nit.First()
+	if nit.Get("Name") == nil || nit.Get("Email") != nil ||
+		!bytes.Equal(nit.Get(keyFieldName), it.Get(keyFieldName)) {
+		t.Fatalf(errUnexpectedResult, "decodeResult")
+	}
+}
+
+func TestQueryCache(t *testing.T) {
+	db := newFakeDB(t)
+	db.SetCache(NewLRUCache(10))
+	q := db.Table("User").Where("Name", "=", "Joe").Cache(time.Minute)
+
+	before := atomic.LoadInt64(&fakeQueried)
+	for i := 0; i < 2; i++ {
+		if _, err := newBuilder(q).run("User", &stmt{statement: bytes.NewBufferString("SELECT * FROM `User`;")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt64(&fakeQueried) - before; n != 1 {
+		t.Fatalf("query should be executed once, but executed %d times", n)
+	}
+
+	db.invalidate("User")
+	if _, err := newBuilder(q).run("User", &stmt{statement: bytes.NewBufferString("SELECT * FROM `User`;")}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(&fakeQueried) - before; n != 2 {
+		t.Fatal("query result cache should be invalidated")
+	}
+
+	txDB := db.clone()
+	tx, _ := db.client.sqlCommon.(*sql.DB).Begin()
+	defer tx.Rollback()
+	txDB.client.sqlCommon = tx
+	if _, err := newBuilder(txDB.Table("User").Cache(time.Minute)).
+		run("User", &stmt{statement: bytes.NewBufferString("SELECT * FROM `User`;")}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(&fakeQueried) - before; n != 3 {
+		t.Fatal("query result cache shouldn't be used in transaction")
+	}
+
+	if _, err := db.Exec("UPDATE `User` SET `Name` = ?;", "Jane"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newBuilder(q).run("User", &stmt{statement: bytes.NewBufferString("SELECT * FROM `User`;")}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(&fakeQueried) - before; n != 4 {
+		t.Fatal("query result cache should be invalidated by exec")
+	}
+}
+
+func TestQueryCacheVersionEvicted(t *testing.T) {
+	db := newFakeDB(t)
+	cache := NewLRUCache(10)
+	db.SetCache(cache)
+	q := db.Table("User").Cache(time.Minute)
+	cmd := &stmt{statement: bytes.NewBufferString("SELECT * FROM `User`;")}
+
+	before := atomic.LoadInt64(&fakeQueried)
+	if _, err := newBuilder(q).run("User", cmd); err != nil {
+		t.Fatal(err)
+	}
+	db.invalidate("User")
+	// the version key of table is evicted from the cache
+	cache.Delete(db.tableVersionKey("User"))
+	if _, err := newBuilder(q).run("User", cmd); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(&fakeQueried) - before; n != 2 {
+		t.Fatal("result cached before the invalidation shouldn't be returned after the version is evicted")
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	stmts := map[string]string{
+		"INSERT INTO `User` VALUES (?);":               "User",
+		" update \"public\".\"User\" SET a = 1":        "User",
+		"DELETE FROM User WHERE 1":                     "User",
+		"REPLACE INTO `db`.`User` SELECT 1":            "User",
+		"TRUNCATE TABLE `User`;":                       "User",
+		"SELECT * FROM `User`;":                        "",
+		"WITH a AS (SELECT 1) UPDATE `User` SET a = 1": "",
+	}
+	for str, table := range stmts {
+		if writeTable(str) != table {
+			t.Fatalf(errUnexpectedResult, "writeTable")
+		}
+	}
+}
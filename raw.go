@@ -0,0 +1,210 @@
+package goloquent
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// RawQuery : the hand-written sql statement, the result is decode as same as the query,
+// use `?` as the placeholder, it will rewrite to the placeholder of the dialect
+type RawQuery struct {
+	db *DB
+	stmt
+}
+
+func newRawQuery(db *DB, query string, args ...interface{}) *RawQuery {
+	return &RawQuery{
+		db: db.clone(),
+		stmt: stmt{
+			statement: bytes.NewBufferString(rewritePlaceholder(query)),
+			arguments: args,
+			crud:      OpRaw,
+		},
+	}
+}
+
+// rewritePlaceholder will replace the `?` placeholder (outside the quoted string) with the variable,
+// so it will bind with the dialect. The query with positional placeholder, eg. `$1`, is not rewritten,
+// so the `?`, `?|` and `?&` operators of postgres jsonb are usable with the positional placeholder
+func rewritePlaceholder(query string) string {
+	if isPositional(query) {
+		return query
+	}
+	buf := new(bytes.Buffer)
+	var quote rune
+	for i, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			// consecutive `?` is same placeholder, eg. `??`
+			if i > 0 && query[i-1] == '?' {
+				continue
+			}
+			buf.WriteString(variable)
+			continue
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// isPositional will check whether the query is using positional placeholder (outside the quoted string), eg. `$1`
+func isPositional(query string) bool {
+	var quote rune
+	for i, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '$':
+			if i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// modelKind will return the kind of the model, the primary key without kind will decode with it
+func modelKind(model interface{}) string {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func (r *RawQuery) run(model interface{}) (*Iterator, error) {
+	return newBuilder(r.db.NewQuery()).run(modelKind(model), &r.stmt)
+}
+
+// Get : decode the records into the slice of model
+func (r *RawQuery) Get(model interface{}) error {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("goloquent: model must be a pointer of slice")
+	}
+	it, err := r.run(model)
+	if err != nil {
+		return err
+	}
+
+	v = v.Elem()
+	vv := reflect.MakeSlice(v.Type(), 0, int(it.Count()))
+	isPtr, t := checkMultiPtr(v)
+	for it.Next() {
+		vi := reflect.New(t)
+		if _, err := it.scan(vi.Interface()); err != nil {
+			return err
+		}
+		if !isPtr {
+			vi = vi.Elem()
+		}
+		vv = reflect.Append(vv, vi)
+	}
+	v.Set(vv)
+	return nil
+}
+
+// First : decode the first record into the model, the model will be zero value if there is no record
+func (r *RawQuery) First(model interface{}) error {
+	if err := checkSinglePtr(model); err != nil {
+		return err
+	}
+	it, err := r.run(model)
+	if err != nil {
+		return err
+	}
+	if it.First() == nil {
+		v := reflect.ValueOf(model)
+		v.Elem().Set(reflect.Zero(v.Type().Elem()))
+		return nil
+	}
+	return it.Scan(model)
+}
+
+// Scan : scan the first record into the destinations, same as `sql.Row.Scan`
+func (r *RawQuery) Scan(dest ...interface{}) error {
+	if err := r.db.client.execQueryRow(&r.stmt).Scan(dest...); err != nil {
+		return fmt.Errorf("goloquent: %v", err)
+	}
+	return nil
+}
+
+// Rows : execute the statement and read the records one by one, the rows must be closed after used
+func (r *RawQuery) Rows() (*RawRows, error) {
+	rows, ss, err := r.db.client.execQuery(&r.stmt)
+	if err != nil {
+		return nil, fmt.Errorf("goloquent: %v", err)
+	}
+	it, err := newBuilder(r.db.NewQuery()).newIterator("", &r.stmt, rows)
+	if err != nil {
+		r.db.client.logRows(ss, 0, err)
+		rows.Close()
+		return nil, err
+	}
+	return &RawRows{db: r.db, rows: rows, ss: ss, it: it}, nil
+}
+
+// RawRows : the streaming result of raw statement
+type RawRows struct {
+	db   *DB
+	rows *sql.Rows
+	// ss is logged when the rows are closed
+	ss  *Stmt
+	n   int64
+	it  *Iterator
+	err error
+}
+
+// Next : read the next record, it return false when there is no more record or error occurs
+func (r *RawRows) Next() bool {
+	if r.err != nil || !r.rows.Next() {
+		return false
+	}
+	r.it.results = r.it.results[:0]
+	if err := r.it.readRow(r.rows, 0); err != nil {
+		r.err = fmt.Errorf("goloquent: %v", err)
+		return false
+	}
+	r.it.position = 0
+	r.n++
+	return true
+}
+
+// Scan : decode the current record into the model
+func (r *RawRows) Scan(model interface{}) error {
+	if r.it.position < 0 || len(r.it.results) <= 0 {
+		return fmt.Errorf("goloquent: scan called without calling Next")
+	}
+	r.it.table = modelKind(model)
+	r.it.patchKey()
+	return r.it.Scan(model)
+}
+
+// Err : return the error encountered during the iteration
+func (r *RawRows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.rows.Err()
+}
+
+// Close :
+func (r *RawRows) Close() error {
+	if r.ss != nil {
+		r.db.client.logRows(r.ss, r.n, r.Err())
+		r.ss = nil
+	}
+	return r.rows.Close()
+}
@@ -0,0 +1,107 @@
+package goloquent
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestRewritePlaceholder(t *testing.T) {
+	stmts := map[string]string{
+		"SELECT * FROM User WHERE Age > ?":                          "SELECT * FROM User WHERE Age > ??",
+		"SELECT * FROM User WHERE Name = ? AND Age > ??":            "SELECT * FROM User WHERE Name = ?? AND Age > ??",
+		"SELECT * FROM User WHERE Name = '?' AND `a?b` = ?":         "SELECT * FROM User WHERE Name = '?' AND `a?b` = ??",
+		`SELECT "Who?" AS Question FROM User WHERE Status IN (?,?)`: `SELECT "Who?" AS Question FROM User WHERE Status IN (??,??)`,
+		`SELECT * FROM "User" WHERE "Tags" ? $1 AND "Tags" ?| $2`:   `SELECT * FROM "User" WHERE "Tags" ? $1 AND "Tags" ?| $2`,
+		"SELECT '$1' AS Price FROM User WHERE Age > ?":              "SELECT '$1' AS Price FROM User WHERE Age > ??",
+	}
+	for str, expect := range stmts {
+		if rewritePlaceholder(str) != expect {
+			t.Fatalf(errUnexpectedResult, "rewritePlaceholder")
+		}
+	}
+}
+
+func TestModelKind(t *testing.T) {
+	type User struct{}
+	if modelKind(new([]*User)) != "User" || modelKind(new(User)) != "User" {
+		t.Fatalf(errUnexpectedResult, "modelKind")
+	}
+}
+
+func TestRawQuery(t *testing.T) {
+	db := newFakeDB(t)
+	setFakeRows(t, []string{pkColumn, parentColumn, "Name"},
+		[]driver.Value{[]byte("'u1'"), []byte(""), []byte("first")},
+		[]driver.Value{[]byte("'u2'"), []byte(""), []byte("second")},
+	)
+
+	users := make([]*testMultiUser, 0)
+	if err := db.Raw("SELECT * FROM `User` WHERE `Name` <> ?", "").Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Name != "first" || users[1].Name != "second" {
+		t.Fatalf(errUnexpectedResult, "RawQuery.Get")
+	}
+	if users[0].Key == nil || users[0].Key.Kind != "testMultiUser" || users[0].Key.Name != "u1" {
+		t.Fatal(`Unexpected result from "RawQuery.Get", key should be loaded with the kind of model`)
+	}
+
+	values := make([]testMultiUser, 0)
+	if err := db.Raw("SELECT * FROM `User`").Get(&values); err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values[1].Name != "second" || values[1].Key == nil || values[1].Key.Name != "u2" {
+		t.Fatalf(errUnexpectedResult, "RawQuery.Get")
+	}
+
+	user := new(testMultiUser)
+	if err := db.Raw("SELECT * FROM `User` LIMIT 1").First(user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "first" || user.Key == nil || user.Key.Name != "u1" {
+		t.Fatalf(errUnexpectedResult, "RawQuery.First")
+	}
+
+	rows, err := db.Raw("SELECT * FROM `User`").Rows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	names := make([]string, 0)
+	for rows.Next() {
+		u := testMultiUser{}
+		if err := rows.Scan(&u); err != nil {
+			t.Fatal(err)
+		}
+		if u.Key == nil || u.Key.Kind != "testMultiUser" {
+			t.Fatal(`Unexpected result from "RawRows.Scan", key should be loaded with the kind of model`)
+		}
+		names = append(names, u.Name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "first" || names[1] != "second" {
+		t.Fatalf(errUnexpectedResult, "RawQuery.Rows")
+	}
+}
+
+func TestRawQueryNoRows(t *testing.T) {
+	db := newFakeDB(t)
+	setFakeRows(t, []string{pkColumn, parentColumn, "Name"})
+
+	user := &testMultiUser{Name: "stale"}
+	if err := db.Raw("SELECT * FROM `User` LIMIT 1").First(user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "" || user.Key != nil {
+		t.Fatal(`Unexpected result from "RawQuery.First", model should be zero value without record`)
+	}
+	users := []testMultiUser{{Name: "stale"}}
+	if err := db.Raw("SELECT * FROM `User`").Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 0 {
+		t.Fatalf(errUnexpectedResult, "RawQuery.Get")
+	}
+}
@@ -1,6 +1,9 @@
 package goloquent
 
-import "reflect"
+import (
+	"reflect"
+	"strings"
+)
 
 var (
 	utf8CharSet    = CharSet{"utf8", "utf8_unicode_ci"}
@@ -27,9 +30,19 @@ type Schema struct {
 	IsNullable   bool
 	IsIndexed    bool
 	CharSet
+	// Enum is the members of enum column
+	Enum []string
 }
 
 // IsOmitEmpty :
 func (s Schema) IsOmitEmpty() bool {
 	return reflect.TypeOf(s.DefaultValue) == reflect.TypeOf(OmitDefault(nil))
 }
+
+func (s Schema) isArray() bool {
+	return strings.HasSuffix(s.DataType, "[]")
+}
+
+func (s Schema) isSpatial() bool {
+	return strings.HasPrefix(strings.ToLower(s.DataType), "point")
+}
@@ -3,13 +3,36 @@ package goloquent
 import (
 	"bytes"
 	"database/sql"
+	"fmt"
+	"runtime"
 	"strings"
 	"time"
 )
 
+// Operation : the operation which generate the statement
+type Operation string
+
+// operations
+const (
+	OpGet      Operation = "get"
+	OpPaginate Operation = "paginate"
+	OpCreate   Operation = "create"
+	OpUpsert   Operation = "upsert"
+	OpSave     Operation = "save"
+	OpUpdate   Operation = "update"
+	OpDelete   Operation = "delete"
+	OpFlush    Operation = "flush"
+	OpTruncate Operation = "truncate"
+	OpDrop     Operation = "drop"
+	OpMigrate  Operation = "migrate"
+	OpRaw      Operation = "raw"
+)
+
 type stmt struct {
 	statement *bytes.Buffer
 	arguments []interface{}
+	crud      Operation
+	table     string
 }
 
 func (s stmt) string() string {
@@ -20,6 +43,8 @@ func (s stmt) isZero() bool {
 	return !(s.statement.Len() > 0)
 }
 
+const modulePath = "github.com/si3nloong/goloquent"
+
 type replacer interface {
 	Bind(uint) string
 	Value(interface{}) string
@@ -28,10 +53,14 @@ type replacer interface {
 // Stmt :
 type Stmt struct {
 	stmt
-	crud      string
 	replacer  replacer
 	startTime time.Time
 	endTime   time.Time
+	rows      int64
+	err       error
+	txID      string
+	caller    string
+	span      Span
 	Result    sql.Result
 }
 
@@ -81,3 +110,63 @@ func (s *Stmt) String() string {
 func (s Stmt) Arguments() []interface{} {
 	return s.arguments
 }
+
+// Operation : the operation which generate the statement, eg. `get`, `update`
+func (s Stmt) Operation() Operation {
+	return s.crud
+}
+
+// Table : the target table of the statement
+func (s Stmt) Table() string {
+	return s.table
+}
+
+// RowsAffected : number of rows affected by the write statement, -1 if it's unavailable
+func (s Stmt) RowsAffected() int64 {
+	if s.Result == nil {
+		return -1
+	}
+	n, err := s.Result.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// RowsReturned : number of rows returned by the query
+func (s Stmt) RowsReturned() int64 {
+	return s.rows
+}
+
+// Err : the error of the statement execution
+func (s Stmt) Err() error {
+	return s.err
+}
+
+// TxID : the id of transaction which the statement executed in, empty if it's not in transaction
+func (s Stmt) TxID() string {
+	return s.txID
+}
+
+// Caller : the file and line which invoke the statement, eg. `user.go:42`
+func (s Stmt) Caller() string {
+	return s.caller
+}
+
+// caller will return the first file:line which is outside of this package
+func caller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		pkg := strings.TrimPrefix(f.Function, modulePath)
+		internal := pkg != f.Function && (strings.HasPrefix(pkg, ".") || strings.HasPrefix(pkg, "/db."))
+		if !internal || strings.HasSuffix(f.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package goloquent
+
+import (
+	"container/list"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// defaultStmtCacheSize is disabling the cache, the statements are prepared on every connection of the pool,
+// the large pool may exceed the statement limit of server, eg. `max_prepared_stmt_count` of mysql
+const defaultStmtCacheSize = 0
+
+type cachedStmt struct {
+	query string
+	stmt  *sql.Stmt
+}
+
+// stmtCache is the LRU cache of prepared statement, keyed by sql statement
+type stmtCache struct {
+	mu    sync.Mutex
+	conn  *sql.DB
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+func newStmtCache(conn *sql.DB, size int) *stmtCache {
+	return &stmtCache{
+		conn:  conn,
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *stmtCache) enabled() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size > 0
+}
+
+// prepare will return the cached statement, the statement will be prepared if it's not in the cache
+func (c *stmtCache) prepare(query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	if e, isOk := c.items[query]; isOk {
+		c.ll.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*cachedStmt).stmt, nil
+	}
+	c.mu.Unlock()
+
+	st, err := c.conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// other goroutine may prepared the same statement
+	if e, isOk := c.items[query]; isOk {
+		st.Close()
+		c.ll.MoveToFront(e)
+		return e.Value.(*cachedStmt).stmt, nil
+	}
+	c.items[query] = c.ll.PushFront(&cachedStmt{query, st})
+	c.evict()
+	return st, nil
+}
+
+func (c *stmtCache) evict() {
+	for c.ll.Len() > c.size {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *stmtCache) removeElement(e *list.Element) {
+	cs := c.ll.Remove(e).(*cachedStmt)
+	delete(c.items, cs.query)
+	// the statement is closed after the rows of it was closed
+	cs.stmt.Close()
+}
+
+// remove the statement from cache
+func (c *stmtCache) remove(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, isOk := c.items[query]; isOk {
+		c.removeElement(e)
+	}
+}
+
+// resize will change the size limit of cache, the cache is disabled if the size is zero
+func (c *stmtCache) resize(size int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if size < 0 {
+		size = 0
+	}
+	c.size = size
+	c.evict()
+}
+
+// purge will close all the cached statement, eg. the table schema is changed
+func (c *stmtCache) purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.ll.Len() > 0 {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// len : number of cached statement
+func (c *stmtCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// isStmtInvalid will check whether the statement should be prepared again
+func isStmtInvalid(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "statement is closed") ||
+		strings.Contains(msg, "needs to be re-prepared") ||
+		strings.Contains(msg, "cached plan must not change result type") ||
+		(strings.Contains(msg, "prepared statement") && strings.Contains(msg, "does not exist"))
+}
@@ -0,0 +1,216 @@
+package goloquent
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+var (
+	fakePrepared, fakeQueried int64
+	fakeMu                    sync.Mutex
+	fakeStatements            []string
+	// fakeResult is the rows returned by every query, it's empty by default, see `setFakeRows`
+	fakeResult fakeRows
+)
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	atomic.AddInt64(&fakePrepared, 1)
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	fakeStatements = append(fakeStatements, query)
+	return fakeStmt{}, nil
+}
+
+// Query is the query without prepared statement, it's used by `sql.DB.Query` when the driver support it
+func (fakeConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	atomic.AddInt64(&fakeQueried, 1)
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	fakeStatements = append(fakeStatements, query)
+	return fakeResult.clone(), nil
+}
+func (fakeConn) Close() error              { return nil }
+func (fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	atomic.AddInt64(&fakeQueried, 1)
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	return fakeResult.clone(), nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r fakeRows) clone() *fakeRows {
+	return &fakeRows{columns: append([]string{}, r.columns...), rows: append([][]driver.Value{}, r.rows...)}
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) <= 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// setFakeRows will set the rows returned by every query until the test is finished
+func setFakeRows(t *testing.T, columns []string, rows ...[]driver.Value) {
+	t.Helper()
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	fakeResult = fakeRows{columns: columns, rows: rows}
+	t.Cleanup(func() {
+		fakeMu.Lock()
+		defer fakeMu.Unlock()
+		fakeResult = fakeRows{}
+	})
+}
+
+func init() {
+	sql.Register("goloquent-fake", fakeDriver{})
+}
+
+// newFakeConn will return the connection of the fake driver, it's closed when the test is finished
+func newFakeConn(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := sql.Open("goloquent-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// newFakeDB will return the DB connected to the fake driver, the dialect is `sequel` if it's not specified
+func newFakeDB(t *testing.T, dialect ...Dialect) *DB {
+	t.Helper()
+	conn := newFakeConn(t)
+	var d Dialect = new(sequel)
+	if len(dialect) > 0 {
+		d = dialect[0]
+	}
+	return NewDB("fake", CharSet{}, conn, d, nil)
+}
+
+func TestStmtCache(t *testing.T) {
+	conn := newFakeConn(t)
+	client := Client{sqlCommon: conn, stmts: newStmtCache(conn, 2)}
+	before := atomic.LoadInt64(&fakePrepared)
+	for i := 0; i < 3; i++ {
+		if _, err := client.PrepareExec("INSERT INTO `A` VALUES (?);", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt64(&fakePrepared) - before; n != 1 {
+		t.Fatalf("statement should be prepared once, but prepared %d times", n)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.PrepareExec(fmt.Sprintf("INSERT INTO `T%d` VALUES (?);", i), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if client.stmts.len() != 2 {
+		t.Fatalf(errUnexpectedResult, "stmtCache.evict")
+	}
+	if _, isOk := client.stmts.items["INSERT INTO `A` VALUES (?);"]; isOk {
+		t.Fatal("least recently used statement should be evicted")
+	}
+
+	tx, err := conn.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txClient := client
+	txClient.sqlCommon = tx
+	txClient.txStmts = new(sync.Map)
+	for i := 0; i < 2; i++ {
+		if _, err := txClient.PrepareExec("INSERT INTO `T2` VALUES (?);", 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	st1 := txClient.txStmt(tx, "INSERT INTO `T2` VALUES (?);", nil)
+	if st2 := txClient.txStmt(tx, "INSERT INTO `T2` VALUES (?);", nil); st1 == nil || st1 != st2 {
+		t.Fatal("statement of transaction should be reused")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.PrepareExec("INSERT INTO `T2` VALUES (?);", 1); err != nil {
+		t.Fatal("cached statement should be usable after transaction,", err)
+	}
+
+	n := client.stmts.len()
+	if err := client.execStmt(&stmt{
+		statement: bytes.NewBufferString("ALTER TABLE `T2` ADD COLUMN `Name` TEXT;"),
+		crud:      OpMigrate,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if client.stmts.len() != n {
+		t.Fatal("schema statement should not be cached")
+	}
+
+	// the query of disabled cache is executed without explicit prepared statement
+	noCache := Client{sqlCommon: conn, stmts: newStmtCache(conn, 0), dialect: new(sequel)}
+	before = atomic.LoadInt64(&fakePrepared)
+	rows, _, err := noCache.execQuery(&stmt{statement: bytes.NewBufferString("SELECT * FROM `A`;")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if n := atomic.LoadInt64(&fakePrepared) - before; n != 0 {
+		t.Fatalf("query should not be prepared when the cache is disabled, but prepared %d times", n)
+	}
+
+	client.stmts.purge()
+	if client.stmts.len() != 0 {
+		t.Fatalf(errUnexpectedResult, "stmtCache.purge")
+	}
+	client.stmts.resize(-1)
+	if client.stmts.enabled() {
+		t.Fatal("statement cache should be disabled")
+	}
+}
+
+func TestIsStmtInvalid(t *testing.T) {
+	if !isStmtInvalid(driver.ErrBadConn) || !isStmtInvalid(fmt.Errorf("wrap: %w", sql.ErrConnDone)) ||
+		!isStmtInvalid(errors.New("sql: statement is closed")) ||
+		!isStmtInvalid(errors.New("pq: cached plan must not change result type")) {
+		t.Fatalf(errUnexpectedResult, "isStmtInvalid")
+	}
+	if isStmtInvalid(errors.New("Error 1062: Duplicate entry")) {
+		t.Fatalf(errUnexpectedResult, "isStmtInvalid")
+	}
+}
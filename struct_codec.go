@@ -19,6 +19,9 @@ var (
 	typeOfGeoPoint       = reflect.TypeOf(datastore.GeoPoint{})
 	typeOfSoftDelete     = reflect.TypeOf(SoftDelete(nil))
 	typeOfJSONRawMessage = reflect.TypeOf(json.RawMessage(nil))
+
+	typeOfPropertyList      = reflect.TypeOf(datastore.PropertyList(nil))
+	typeOfPropertyLoadSaver = reflect.TypeOf((*datastore.PropertyLoadSaver)(nil)).Elem()
 )
 
 type field struct {
@@ -134,6 +137,8 @@ func isReserveFieldName(name string) bool {
 	m := map[string]bool{
 		strings.ToLower(pkColumn):         true,
 		strings.ToLower(softDeleteColumn): true,
+		strings.ToLower(parentColumn):     true,
+		strings.ToLower(overflowColumn):   true,
 	}
 	return m[strings.ToLower(name)]
 }
@@ -141,6 +146,8 @@ func isReserveFieldName(name string) bool {
 func isBaseType(t reflect.Type) bool {
 	k := t.Kind()
 	switch true {
+	case codecOf(t) != nil:
+		return true
 	case k == reflect.String:
 		return true
 	case t == typeOfByte:
@@ -157,7 +164,7 @@ func isBaseType(t reflect.Type) bool {
 		return true
 	case t == typeOfPtrKey || t == typeOfTime || t == typeOfGeoPoint:
 		return true
-	case t == typeOfDate:
+	case t == typeOfDate, t == typeOfDecimal:
 		return true
 	case t == typeOfSoftDelete:
 		return true
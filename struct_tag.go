@@ -3,54 +3,102 @@ package goloquent
 import (
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 type tag struct {
-	name    string
-	options map[string]bool
-	others  map[string]string
+	name        string
+	options     map[string]bool
+	others      map[string]string
+	jsonIndexes []string
+	// nullEmpty is the `omitempty` of datastore tag, the zero value is not stored
+	nullEmpty bool
 }
 
 // TODO: Eager loading tag
 
+// tagNames is the precedence of struct tag, only the first present tag is used, they are not merged
+var tagNames = []string{"goloquent", "datastore"}
+
 func newTag(sf reflect.StructField) tag {
 	name := sf.Name
-
-	t := strings.TrimSpace(sf.Tag.Get("goloquent"))
-	paths := strings.Split(t, ",")
-	if strings.TrimSpace(paths[0]) != "" {
-		name = paths[0]
-	}
-
 	options := map[string]bool{
 		"index":     false,
+		"noindex":   false,
 		"flatten":   false,
 		"omitempty": false,
 		"unsigned":  false,
 		"longtext":  false,
+		"fulltext":  false,
+		// time is stored as `timestamptz` in postgres
+		"timestamptz": false,
+		// scalar slice is stored as native array in postgres, eg. `text[]`
+		"array": false,
 	}
 
 	others := make(map[string]string)
-	paths = paths[1:]
-	for _, k := range paths {
-		k = strings.ToLower(k)
-		if _, isValid := options[k]; isValid {
-			options[k] = true
-		} else {
-			rgx := regexp.MustCompile(`(datatype|charset|collate)\=.+`)
-			if rgx.MatchString(k) {
-				rgx = regexp.MustCompile(`(\w+)=(.+)`)
-				result := rgx.FindStringSubmatch(k)
-				others[result[1]] = result[2]
+	jsonIndexes := make([]string, 0)
+	nullEmpty := false
+	for _, tn := range tagNames {
+		t, isOk := sf.Tag.Lookup(tn)
+		if !isOk {
+			continue
+		}
+		paths := strings.Split(strings.TrimSpace(t), ",")
+		if strings.TrimSpace(paths[0]) != "" {
+			name = paths[0]
+		}
+
+		paths = paths[1:]
+		for _, k := range paths {
+			// json path is case sensitive, so it cannot be lower case
+			if strings.HasPrefix(strings.ToLower(k), "index=") {
+				if path := strings.TrimSpace(k[len("index="):]); path != "" {
+					jsonIndexes = append(jsonIndexes, path)
+				}
+				continue
+			}
+			// time zone name is case sensitive as well, eg. `location=Asia/Kuala_Lumpur`
+			if strings.HasPrefix(strings.ToLower(k), "location=") {
+				others["location"] = strings.TrimSpace(k[len("location="):])
+				continue
+			}
+			// enum members are case sensitive, eg. `enum=Active|Suspended`
+			if strings.HasPrefix(strings.ToLower(k), "enum=") {
+				others["enum"] = strings.TrimSpace(k[len("enum="):])
+				continue
+			}
+			k = strings.ToLower(k)
+			if tn == "datastore" && k == "omitempty" {
+				nullEmpty = true
+			}
+			if _, isValid := options[k]; isValid {
+				options[k] = true
+				switch k {
+				case "index":
+					options["noindex"] = false
+				case "noindex":
+					options["index"] = false
+				}
+			} else {
+				rgx := regexp.MustCompile(`(datatype|charset|collate|fulltext|precision|scale)\=.+`)
+				if rgx.MatchString(k) {
+					rgx = regexp.MustCompile(`(\w+)=(.+)`)
+					result := rgx.FindStringSubmatch(k)
+					others[result[1]] = result[2]
+				}
 			}
 		}
+		break
 	}
 
 	return tag{
-		name:    name,
-		options: options,
-		others:  others,
+		name:        name,
+		options:     options,
+		others:      others,
+		jsonIndexes: jsonIndexes,
+		nullEmpty:   nullEmpty,
 	}
 }
 
@@ -74,10 +122,35 @@ func (t tag) IsIndex() bool {
 	return t.options["index"]
 }
 
+func (t tag) IsNoIndex() bool {
+	return t.options["noindex"]
+}
+
+// JSONIndexes : the json paths which need to be indexed
+func (t tag) JSONIndexes() []string {
+	return t.jsonIndexes
+}
+
+// IsFullText :
+func (t tag) IsFullText() bool {
+	return t.options["fulltext"] || t.others["fulltext"] != ""
+}
+
+// FullTextLanguage : the text search configuration of postgres, eg. `fulltext=english`
+func (t tag) FullTextLanguage() string {
+	return t.others["fulltext"]
+}
+
 func (t tag) IsOmitEmpty() bool {
 	return t.options["omitempty"]
 }
 
+// isNullEmpty : the column is nullable and the zero value is stored as null,
+// it's only applicable for `omitempty` of datastore tag, `omitempty` of goloquent tag is unchanged
+func (t tag) isNullEmpty() bool {
+	return t.nullEmpty
+}
+
 func (t tag) IsUnsigned() bool {
 	return t.options["unsigned"]
 }
@@ -85,3 +158,28 @@ func (t tag) IsUnsigned() bool {
 func (t tag) IsLongText() bool {
 	return t.options["longtext"]
 }
+
+// isArray :
+func (t tag) isArray() bool {
+	return t.options["array"]
+}
+
+// decimalSize : the precision and scale of decimal column, eg. `precision=20,scale=4`
+func (t tag) decimalSize() (int, int) {
+	p, s := defaultDecimalPrecision, defaultDecimalScale
+	if v, err := strconv.Atoi(t.Get("precision")); err == nil && v > 0 {
+		p = v
+	}
+	if v, err := strconv.Atoi(t.Get("scale")); err == nil && v >= 0 {
+		s = v
+	}
+	if s > p {
+		s = p
+	}
+	return p, s
+}
+
+// isDecimal will check whether the float is stored as decimal, it's decimal when the precision is specified
+func (t tag) isDecimal() bool {
+	return t.Get("precision") != ""
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+
+	"cloud.google.com/go/datastore"
 )
 
 func TestStructTagWithSkip(t *testing.T) {
@@ -42,3 +44,130 @@ func TestStructTagWithIndex(t *testing.T) {
 		t.Fatal("Expected tag have index, but end up with noindex")
 	}
 }
+
+func TestStructTagWithDatastore(t *testing.T) {
+	var i struct {
+		Name    string         `datastore:"name,noindex"`
+		Parent  *datastore.Key `datastore:",noindex" goloquent:"ParentKey,index"`
+		Address struct {
+			Line1 string
+		} `datastore:",flatten,omitempty"`
+		Skip string `datastore:"-"`
+		Bio  string `datastore:"bio,noindex" goloquent:",longtext"`
+	}
+	vt := reflect.ValueOf(i).Type()
+	tag := newTag(vt.Field(0))
+	if tag.name != "name" || !tag.IsNoIndex() {
+		t.Fatalf("Expected tag name %q with noindex, but end up with %v", "name", tag.name)
+	}
+	tag = newTag(vt.Field(1))
+	if tag.name != "ParentKey" || !tag.IsIndex() || tag.IsNoIndex() {
+		t.Fatal(`Expected "goloquent" tag override "datastore" tag`)
+	}
+	tag = newTag(vt.Field(2))
+	if tag.name != "Address" || !tag.isFlatten() || !tag.IsOmitEmpty() {
+		t.Fatal("Expected tag have flatten and omitempty of datastore")
+	}
+	tag = newTag(vt.Field(3))
+	if !tag.isSkip() {
+		t.Fatal("Expected tag have skip")
+	}
+	tag = newTag(vt.Field(4))
+	if tag.name != "Bio" || !tag.IsLongText() || tag.IsNoIndex() {
+		t.Fatal(`Expected "datastore" tag is ignored when "goloquent" tag is present`)
+	}
+}
+
+func TestStructTagWithJSONIndex(t *testing.T) {
+	var i struct {
+		Address struct {
+			Line1  string
+			Region struct {
+				RegionCode string `goloquent:"regionCode"`
+			} `goloquent:"region"`
+		} `goloquent:"address,index=region.regionCode,index=Line1"`
+	}
+	vt := reflect.ValueOf(i).Type()
+	tag := newTag(vt.Field(0))
+	if tag.name != "address" || tag.IsIndex() {
+		t.Fatal("Expected tag have no index")
+	}
+	if !reflect.DeepEqual(tag.JSONIndexes(), []string{"region.regionCode", "Line1"}) {
+		t.Fatalf("Expected tag have json indexes, but end up with %v", tag.JSONIndexes())
+	}
+}
+
+func TestStructTagWithFullText(t *testing.T) {
+	var i struct {
+		Title string `goloquent:",fulltext"`
+		Body  string `goloquent:",longtext,fulltext=english"`
+	}
+	vt := reflect.ValueOf(i).Type()
+	tag := newTag(vt.Field(0))
+	if !tag.IsFullText() || tag.FullTextLanguage() != "" {
+		t.Fatal("Expected tag have fulltext")
+	}
+	tag = newTag(vt.Field(1))
+	if !tag.IsFullText() || tag.FullTextLanguage() != "english" || !tag.IsLongText() {
+		t.Fatal("Expected tag have fulltext with language english")
+	}
+}
+
+func TestOmitEmpty(t *testing.T) {
+	type testProfile struct {
+		Key      *datastore.Key `goloquent:"__key__"`
+		Nickname string         `datastore:"nickname,omitempty"`
+		Age      int            `goloquent:",omitempty"`
+		Name     string
+	}
+
+	db := newFakeDB(t)
+	e, err := newEntity(&[]testProfile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range e.columns {
+		for _, ss := range db.dialect.GetSchema(c) {
+			isNullable, isOk := map[string]bool{"nickname": true, "Age": false, "Name": false}[ss.Name]
+			if isOk && ss.IsNullable != isNullable {
+				t.Fatalf("nullable of column %q should be %v", ss.Name, isNullable)
+			}
+		}
+	}
+
+	// the zero value of `omitempty` field of datastore tag is stored as null,
+	// `omitempty` of goloquent tag keeps storing the zero value
+	b := newBuilder(db.NewQuery())
+	props, err := SaveStruct(&testProfile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, expect := range map[string]interface{}{"nickname": nil, "Age": int64(0), "Name": ""} {
+		if v, err := b.propertyValue(props[name]); err != nil || v != expect {
+			t.Fatalf("unexpected value of %q, %v", name, v)
+		}
+	}
+	props, err = SaveStruct(&testProfile{Nickname: "Sam", Age: 18})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := b.propertyValue(props["nickname"]); v != "Sam" {
+		t.Fatalf(errUnexpectedResult, "propertyValue")
+	}
+	if v, _ := b.propertyValue(props["Age"]); v != int64(18) {
+		t.Fatalf(errUnexpectedResult, "propertyValue")
+	}
+
+	// null is loaded as zero value
+	it := &Iterator{
+		columns: []string{keyFieldName, "nickname", "Age", "Name"},
+		results: []map[string][]byte{{"nickname": nil, "Age": nil, "Name": []byte("Sam")}},
+	}
+	p := testProfile{Nickname: "x", Age: 1}
+	if _, err := it.scan(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Nickname != "" || p.Age != 0 || p.Name != "Sam" {
+		t.Fatalf(errUnexpectedResult, "Iterator.scan")
+	}
+}
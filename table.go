@@ -1,6 +1,8 @@
 package goloquent
 
 import (
+	"time"
+
 	"cloud.google.com/go/datastore"
 )
 
@@ -61,6 +63,11 @@ func (t *Table) AddUniqueIndex(fields ...string) error {
 	return newBuilder(t.newQuery()).addIndex(fields, uniqueIdx)
 }
 
+// AddJSONIndex : index the json path of the field, eg. AddJSONIndex("Address", "region.regionCode")
+func (t *Table) AddJSONIndex(field, path string) error {
+	return newBuilder(t.newQuery()).addJSONIndex(t.name, field, path)
+}
+
 // Select :
 func (t *Table) Select(fields ...string) *Query {
 	return t.newQuery().Select(fields...)
@@ -86,6 +93,11 @@ func (t *Table) Find(key *datastore.Key, model interface{}) error {
 	return t.newQuery().Find(key, model)
 }
 
+// GetMulti :
+func (t *Table) GetMulti(keys []*datastore.Key, model interface{}) error {
+	return t.newQuery().GetMulti(keys, model)
+}
+
 // First :
 func (t *Table) First(model interface{}) error {
 	return t.newQuery().First(model)
@@ -96,11 +108,51 @@ func (t *Table) Get(model interface{}) error {
 	return t.newQuery().Get(model)
 }
 
+// Keys :
+func (t *Table) Keys() ([]*datastore.Key, error) {
+	return t.newQuery().Keys()
+}
+
+// KeysOnly :
+func (t *Table) KeysOnly() (*Iterator, error) {
+	return t.newQuery().KeysOnly()
+}
+
 // Paginate :
 func (t *Table) Paginate(p *Pagination, model interface{}) error {
 	return t.newQuery().Paginate(p, model)
 }
 
+// ToSQL :
+func (t *Table) ToSQL(op Operation, args ...interface{}) (*Stmt, error) {
+	return t.newQuery().ToSQL(op, args...)
+}
+
+// Explain :
+func (t *Table) Explain() (*Plan, error) {
+	return t.newQuery().Explain()
+}
+
+// Cache :
+func (t *Table) Cache(ttl time.Duration) *Query {
+	return t.newQuery().Cache(ttl)
+}
+
+// As :
+func (t *Table) As(alias string) *Query {
+	return t.newQuery().As(alias)
+}
+
+// Join :
+func (t *Table) Join(table, leftField, op, rightField string) *Query {
+	return t.newQuery().Join(table, leftField, op, rightField)
+}
+
+// LeftJoin :
+func (t *Table) LeftJoin(table, leftField, op, rightField string) *Query {
+	return t.newQuery().LeftJoin(table, leftField, op, rightField)
+}
+
 // AnyOfAncestor :
 func (t *Table) AnyOfAncestor(ancestors ...*datastore.Key) *Query {
 	return t.newQuery().AnyOfAncestor(ancestors...)
@@ -111,6 +163,11 @@ func (t *Table) Ancestor(ancestor *datastore.Key) *Query {
 	return t.newQuery().Ancestor(ancestor)
 }
 
+// Descendants :
+func (t *Table) Descendants(key *datastore.Key, depth int) *Query {
+	return t.newQuery().Descendants(key, depth)
+}
+
 // Where :
 func (t *Table) Where(field, op string, value interface{}) *Query {
 	return t.newQuery().Where(field, op, value)
@@ -146,6 +203,36 @@ func (t *Table) WhereNotIn(field string, v []interface{}) *Query {
 	return t.newQuery().WhereNotIn(field, v)
 }
 
+// WhereNear :
+func (t *Table) WhereNear(field string, point datastore.GeoPoint, meters float64) *Query {
+	return t.newQuery().WhereNear(field, point, meters)
+}
+
+// WhereWithinBox :
+func (t *Table) WhereWithinBox(field string, sw, ne datastore.GeoPoint) *Query {
+	return t.newQuery().WhereWithinBox(field, sw, ne)
+}
+
+// WhereContains :
+func (t *Table) WhereContains(field string, v interface{}) *Query {
+	return t.newQuery().WhereContains(field, v)
+}
+
+// WhereOverlaps :
+func (t *Table) WhereOverlaps(field string, v interface{}) *Query {
+	return t.newQuery().WhereOverlaps(field, v)
+}
+
+// WhereContainedBy :
+func (t *Table) WhereContainedBy(field string, v interface{}) *Query {
+	return t.newQuery().WhereContainedBy(field, v)
+}
+
+// Match :
+func (t *Table) Match(fields []string, query string, mode SearchMode, language ...string) *Query {
+	return t.newQuery().Match(fields, query, mode, language...)
+}
+
 // WhereLike :
 func (t *Table) WhereLike(field, v string) *Query {
 	return t.newQuery().WhereLike(field, v)
@@ -12,6 +12,7 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/si3nloong/goloquent"
 	"github.com/si3nloong/goloquent/db"
+	"github.com/si3nloong/goloquent/expr"
 )
 
 func TestMySQLConn(t *testing.T) {
@@ -62,6 +63,10 @@ func TestMySQLAddIndex(t *testing.T) {
 		AddIndex("Age"); err != nil {
 		t.Fatal(err)
 	}
+	if err := my.Table("User").
+		AddJSONIndex("Address", "region.regionCode"); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func TestMySQLEmptyInsertOrUpsert(t *testing.T) {
@@ -258,6 +263,24 @@ func TestMySQLGet(t *testing.T) {
 	if u.Key == nil {
 		t.Fatal("unexpected result")
 	}
+
+	missingKey := datastore.NameKey("User", "missing", nil)
+	keys := []*datastore.Key{u2.Key, missingKey, u.Key}
+	users = new([]User)
+	err := my.GetMulti(keys, users)
+	merr, isOk := err.(goloquent.MultiError)
+	if !isOk {
+		t.Fatalf("Unexpected error, %v", err)
+	}
+	if len(*users) != len(keys) || merr[0] != nil || merr[2] != nil {
+		t.Fatal(`Unexpected result from "GetMulti"`)
+	}
+	if merr[1] != goloquent.ErrNoSuchEntity {
+		t.Fatal(`Unexpected result from "GetMulti", missing key should return "ErrNoSuchEntity"`)
+	}
+	if !(*users)[0].Key.Equal(u2.Key) || !(*users)[2].Key.Equal(u.Key) {
+		t.Fatal(`Unexpected result from "GetMulti", result should follow the order of keys`)
+	}
 }
 
 func TestMySQLAncestor(t *testing.T) {
@@ -288,6 +311,13 @@ func TestMySQLAncestor(t *testing.T) {
 	if len(*users) <= 0 {
 		t.Fatal(`Unexpected result from filter "Ancestor" using name key with symbol`)
 	}
+
+	if err := my.Descendants(idKey, 1).Get(users); err != nil {
+		t.Fatal(err)
+	}
+	if len(*users) <= 0 {
+		t.Fatal(`Unexpected result from filter "Descendants" using id key`)
+	}
 }
 
 func TestMySQLWhereFilter(t *testing.T) {
@@ -338,6 +368,161 @@ func TestMySQLWhereFilter(t *testing.T) {
 	}
 }
 
+func TestMySQLWhereNear(t *testing.T) {
+	type Store struct {
+		Key      *datastore.Key `goloquent:"__key__"`
+		Name     string
+		Location datastore.GeoPoint
+	}
+
+	if err := my.Migrate(new(Store)); err != nil {
+		t.Fatal(err)
+	}
+
+	point := datastore.GeoPoint{Lat: 3.139003, Lng: 101.686855}
+	stores := []*Store{
+		{Name: "near", Location: point},
+		{Name: "far", Location: datastore.GeoPoint{Lat: 1.352083, Lng: 103.819836}},
+	}
+	if err := my.Create(&stores); err != nil {
+		t.Fatal(err)
+	}
+
+	result := new([]Store)
+	if err := my.WhereNear("Location", point, 1000).
+		OrderBy(expr.Distance{Name: "Location", Lat: point.Lat, Lng: point.Lng}).
+		Get(result); err != nil {
+		t.Fatal(err)
+	}
+	if len(*result) != 1 || (*result)[0].Location != point {
+		t.Fatal(`Unexpected result from filter using "WhereNear"`)
+	}
+
+	if err := my.WhereWithinBox("Location",
+		datastore.GeoPoint{Lat: 1, Lng: 101},
+		datastore.GeoPoint{Lat: 4, Lng: 104}).
+		Get(result); err != nil {
+		t.Fatal(err)
+	}
+	if len(*result) != 2 {
+		t.Fatal(`Unexpected result from filter using "WhereWithinBox"`)
+	}
+}
+
+func TestMySQLMatch(t *testing.T) {
+	type Article struct {
+		Key   *datastore.Key `goloquent:"__key__"`
+		Title string         `goloquent:",fulltext"`
+		Body  string         `goloquent:",longtext,fulltext"`
+	}
+
+	if err := my.Migrate(new(Article)); err != nil {
+		t.Fatal(err)
+	}
+	if err := my.Create(&Article{Title: "Goloquent", Body: "The full text search of database"}); err != nil {
+		t.Fatal(err)
+	}
+
+	articles := new([]Article)
+	if err := my.Match([]string{"Title", "Body"}, "database", goloquent.NaturalLanguageMode).
+		OrderBy(expr.Relevance{Fields: []string{"Title", "Body"}, Query: "database", Direction: expr.Descending}).
+		Get(articles); err != nil {
+		t.Fatal(err)
+	}
+	if len(*articles) <= 0 {
+		t.Fatal(`Unexpected result from filter using "Match"`)
+	}
+
+	if err := my.Match([]string{"Body"}, "+full -goloquent", goloquent.BooleanMode).
+		Get(articles); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMySQLJoin(t *testing.T) {
+	type Merchant struct {
+		Key  *datastore.Key `goloquent:"__key__"`
+		Name string
+	}
+	type Outlet struct {
+		Key         *datastore.Key `goloquent:"__key__"`
+		MerchantKey *datastore.Key
+		Name        string
+	}
+	type OutletMerchant struct {
+		Outlet       `goloquent:"o"`
+		Merchant     *Merchant `goloquent:"m"`
+		MerchantName string    `goloquent:"m.Name"`
+	}
+
+	if err := my.Migrate(new(Merchant), new(Outlet)); err != nil {
+		t.Fatal(err)
+	}
+	m := &Merchant{Name: "Goloquent"}
+	if err := my.Create(m); err != nil {
+		t.Fatal(err)
+	}
+	if err := my.Create(&[]*Outlet{
+		{MerchantKey: m.Key, Name: "Outlet A"},
+		{Name: "Outlet B"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	results := new([]OutletMerchant)
+	if err := my.Table("Outlet").As("o").
+		LeftJoin("Merchant AS m", "o.MerchantKey", "=", "m.__key__").
+		OrderBy("o.Name").
+		Get(results); err != nil {
+		t.Fatal(err)
+	}
+	if len(*results) < 2 {
+		t.Fatal(`Unexpected result from "LeftJoin"`)
+	}
+
+	result := new(OutletMerchant)
+	if err := my.Table("Outlet").As("o").
+		Join("Merchant m", "o.MerchantKey", "=", "m.__key__").
+		Where("m.Name", "=", "Goloquent").
+		First(result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Merchant == nil || result.MerchantName != "Goloquent" ||
+		result.Outlet.MerchantKey == nil || !result.Outlet.MerchantKey.Equal(m.Key) {
+		t.Fatal(`Unexpected result from "Join"`)
+	}
+}
+
+func TestMySQLRaw(t *testing.T) {
+	users := new([]User)
+	if err := my.Raw("SELECT * FROM `User` WHERE `Age` >= ? LIMIT 10", 0).Get(users); err != nil {
+		t.Fatal(err)
+	}
+	if len(*users) <= 0 || (*users)[0].Key == nil {
+		t.Fatal(`Unexpected result from "Raw"`)
+	}
+
+	rows, err := my.Raw("SELECT * FROM `User` LIMIT 10").Rows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		u := new(User)
+		if err := rows.Scan(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	if err := my.Raw("SELECT COUNT(*) FROM `User`").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestMySQLWhereAnyLike(t *testing.T) {
 	users := new([]User)
 
@@ -658,6 +843,15 @@ func TestMySQLUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	if err := my.Table("User").Limit(1).
+		Update(map[string]interface{}{
+			"Address>region>regionCode": "MY",
+			"Address>Line2":             goloquent.JSONRemove(),
+			"Address>region>keys":       goloquent.JSONArrayAppend("key"),
+		}); err != nil {
+		t.Fatal(err)
+	}
+
 	// TODO: support struct
 	// if err := my.Table("User").Limit(1).
 	// 	Update(map[string]interface{}{
@@ -738,6 +932,30 @@ func TestMySQLScan(t *testing.T) {
 	log.Println("Count :", count, ", Sum :", sum)
 }
 
+func TestMySQLPropertyList(t *testing.T) {
+	if err := my.Table("Dynamic").Migrate(new(datastore.PropertyList)); err != nil {
+		t.Fatal(err)
+	}
+
+	k := datastore.NameKey("Dynamic", "dynamic", nil)
+	pl := datastore.PropertyList{
+		{Name: "__key__", Value: k},
+		{Name: "Name", Value: "goloquent"},
+		{Name: "Tags", Value: []interface{}{"a", int64(1)}},
+	}
+	if err := my.Table("Dynamic").Upsert(&pl); err != nil {
+		t.Fatal(err)
+	}
+
+	result := new(datastore.PropertyList)
+	if err := my.Find(k, result); err != nil {
+		t.Fatal(err)
+	}
+	if len(*result) != len(pl) {
+		t.Fatal(`Unexpected result from "PropertyList"`)
+	}
+}
+
 func TestMySQLClose(t *testing.T) {
 	defer my.Close()
 }
@@ -7,9 +7,11 @@ import (
 	"testing"
 	"time"
 
+	"cloud.google.com/go/datastore"
 	_ "github.com/lib/pq"
 	"github.com/si3nloong/goloquent"
 	"github.com/si3nloong/goloquent/db"
+	"github.com/si3nloong/goloquent/expr"
 )
 
 var (
@@ -427,6 +429,257 @@ func TestPostgresJSONIsArray(t *testing.T) {
 	}
 }
 
+func TestPostgresWhereNear(t *testing.T) {
+	type Store struct {
+		Key      *datastore.Key `goloquent:"__key__"`
+		Name     string
+		Location datastore.GeoPoint `goloquent:",index"`
+	}
+
+	if err := pg.Migrate(new(Store)); err != nil {
+		t.Fatal(err)
+	}
+
+	point := datastore.GeoPoint{Lat: 3.139003, Lng: 101.686855}
+	stores := []*Store{
+		{Name: "near", Location: point},
+		{Name: "far", Location: datastore.GeoPoint{Lat: 1.352083, Lng: 103.819836}},
+	}
+	if err := pg.Create(&stores); err != nil {
+		t.Fatal(err)
+	}
+
+	result := new([]Store)
+	if err := pg.WhereNear("Location", point, 1000).
+		OrderBy(expr.Distance{Name: "Location", Lat: point.Lat, Lng: point.Lng}).
+		Get(result); err != nil {
+		t.Fatal(err)
+	}
+	if len(*result) != 1 || (*result)[0].Location != point {
+		t.Fatal(`Unexpected result from filter using "WhereNear"`)
+	}
+
+	if err := pg.WhereWithinBox("Location",
+		datastore.GeoPoint{Lat: 1, Lng: 101},
+		datastore.GeoPoint{Lat: 4, Lng: 104}).
+		Get(result); err != nil {
+		t.Fatal(err)
+	}
+	if len(*result) != 2 {
+		t.Fatal(`Unexpected result from filter using "WhereWithinBox"`)
+	}
+}
+
+func TestPostgresJSONIndex(t *testing.T) {
+	type Profile struct {
+		Key     *datastore.Key `goloquent:"__key__"`
+		Address Address        `goloquent:",index=region.regionCode"`
+		Tags    map[string]interface{}
+	}
+
+	if err := pg.Migrate(new(Profile)); err != nil {
+		t.Fatal(err)
+	}
+	// the second migration must not recreate the existing index
+	if err := pg.Migrate(new(Profile)); err != nil {
+		t.Fatal(err)
+	}
+
+	p := new(Profile)
+	p.Address.Region.CountryCode = "MY"
+	p.Tags = map[string]interface{}{"vip": true, "labels": []interface{}{"a", "b"}}
+	if err := pg.Create(p); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles := new([]Profile)
+	if err := pg.NewQuery().WhereJSONIn("Address>region.regionCode", []interface{}{"MY", "SG"}).
+		Get(profiles); err != nil {
+		t.Fatal(err)
+	}
+	if len(*profiles) != 1 {
+		t.Fatal(`Unexpected result from filter "WhereJSONIn" using indexed json path`)
+	}
+
+	// the bool and array values of non-indexed path are compared as json
+	if err := pg.NewQuery().WhereJSONIn("Tags>vip", []interface{}{true}).
+		Get(profiles); err != nil {
+		t.Fatal(err)
+	}
+	if len(*profiles) != 1 {
+		t.Fatal(`Unexpected result from filter "WhereJSONIn" using bool value`)
+	}
+	if err := pg.NewQuery().WhereJSONIn("Tags>labels", []interface{}{[]string{"a", "b"}}).
+		Get(profiles); err != nil {
+		t.Fatal(err)
+	}
+	if len(*profiles) != 1 {
+		t.Fatal(`Unexpected result from filter "WhereJSONIn" using array value`)
+	}
+}
+
+func TestPostgresJSONUpdate(t *testing.T) {
+	u := getFakeUser()
+	u.Address.Line2 = "Taman Desa"
+	if err := pg.Create(u); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pg.Table("User").
+		Where("Username", "=", u.Username).
+		Update(map[string]interface{}{
+			"Address>region>regionCode": "MY",
+			"Address>Line2":             goloquent.JSONRemove(),
+			"Address>Line1":             goloquent.JSONSet("7813, Jalan Section 22"),
+		}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := new(User)
+	if err := pg.Where("Username", "=", u.Username).First(result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Address.Region.CountryCode != "MY" ||
+		result.Address.Line1 != "7813, Jalan Section 22" ||
+		result.Address.Line2 != "" ||
+		result.Address.Country != u.Address.Country {
+		t.Fatal(`Unexpected result from partial json update using "jsonb_set"`)
+	}
+}
+
+func TestPostgresMatch(t *testing.T) {
+	type Article struct {
+		Key   *datastore.Key `goloquent:"__key__"`
+		Title string         `goloquent:",fulltext"`
+		Body  string         `goloquent:",longtext,fulltext"`
+	}
+
+	if err := pg.Migrate(new(Article)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pg.Create(&Article{Title: "Goloquent", Body: "The full text search of database"}); err != nil {
+		t.Fatal(err)
+	}
+
+	articles := new([]Article)
+	if err := pg.Match([]string{"Title", "Body"}, "databases", goloquent.NaturalLanguageMode, "english").
+		OrderBy(expr.Relevance{Fields: []string{"Title", "Body"}, Query: "databases", Direction: expr.Descending}).
+		Get(articles); err != nil {
+		t.Fatal(err)
+	}
+	if len(*articles) <= 0 {
+		t.Fatal(`Unexpected result from filter using "Match"`)
+	}
+
+	if err := pg.Match([]string{"Body"}, "full -goloquent", goloquent.BooleanMode).
+		Get(articles); err != nil {
+		t.Fatal(err)
+	}
+	if len(*articles) <= 0 {
+		t.Fatal(`Unexpected result from filter using "Match" in boolean mode`)
+	}
+}
+
+func TestPostgresArray(t *testing.T) {
+	type Member struct {
+		Key       *datastore.Key `goloquent:"__key__"`
+		Nicknames []string       `goloquent:",array"`
+		Scores    []int64        `goloquent:",array"`
+	}
+
+	if err := pg.Migrate(new(Member)); err != nil {
+		t.Fatal(err)
+	}
+	members := []*Member{
+		{Nicknames: []string{"Sam", "Sammy"}, Scores: []int64{1, 2}},
+		{Nicknames: []string{"Tom"}, Scores: []int64{}},
+	}
+	if err := pg.Create(&members); err != nil {
+		t.Fatal(err)
+	}
+
+	result := new([]Member)
+	if err := pg.WhereContains("Nicknames", []string{"Sam", "Sammy"}).
+		Get(result); err != nil {
+		t.Fatal(err)
+	}
+	if len(*result) != 1 || len((*result)[0].Nicknames) != 2 {
+		t.Fatal(`Unexpected result from filter using "WhereContains"`)
+	}
+
+	if err := pg.WhereOverlaps("Nicknames", []string{"Tom", "Jerry"}).
+		Get(result); err != nil {
+		t.Fatal(err)
+	}
+	if len(*result) != 1 || (*result)[0].Nicknames[0] != "Tom" {
+		t.Fatal(`Unexpected result from filter using "WhereOverlaps"`)
+	}
+
+	if err := pg.WhereContainedBy("Scores", []int64{1, 2, 3}).
+		Get(result); err != nil {
+		t.Fatal(err)
+	}
+	if len(*result) != 2 {
+		t.Fatal(`Unexpected result from filter using "WhereContainedBy"`)
+	}
+}
+
+func TestPostgresEnum(t *testing.T) {
+	{
+		type Account struct {
+			Key    *datastore.Key `goloquent:"__key__"`
+			Status string         `goloquent:",enum=active|suspended"`
+		}
+
+		if err := pg.Table("Account").DropIfExists(); err != nil {
+			t.Fatal(err)
+		}
+		if err := pg.Migrate(new(Account)); err != nil {
+			t.Fatal(err)
+		}
+		if err := pg.Create(&Account{Status: "active"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := pg.Create(&Account{Status: "deleted"}); err == nil {
+			t.Fatal("invalid enum value should return error")
+		}
+		// the raw statement is rejected by the check constraint
+		if _, err := pg.Exec(`INSERT INTO "Account" ("$Key", "$Parent", "Status") VALUES ('deleted', '', 'deleted');`); err == nil {
+			t.Fatal("invalid enum value should be rejected by check constraint")
+		}
+	}
+
+	{
+		// the new member is added to the check constraint
+		type Account struct {
+			Key    *datastore.Key `goloquent:"__key__"`
+			Status string         `goloquent:",enum=active|suspended|deleted"`
+		}
+
+		if err := pg.Migrate(new(Account)); err != nil {
+			t.Fatal(err)
+		}
+		if err := pg.Create(&Account{Status: "deleted"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	{
+		// the check constraint is dropped when the enum option is removed
+		type Account struct {
+			Key    *datastore.Key `goloquent:"__key__"`
+			Status string
+		}
+
+		if err := pg.Migrate(new(Account)); err != nil {
+			t.Fatal(err)
+		}
+		if err := pg.Create(&Account{Status: "archived"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
 func TestPostgresPaginate(t *testing.T) {
 	users := new([]User)
 
@@ -0,0 +1,130 @@
+package goloquent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timeLayout is the layout of date time with full precision, the trailing zeros of fractional seconds are omitted
+const timeLayout = "2006-01-02 15:04:05.999999"
+
+// TimeConfig : the storage of `time.Time`, the time is always normalised to UTC on write.
+// It can be overridden by the tag of field, eg. `goloquent:",precision=6,timestamptz,location=Asia/Kuala_Lumpur"`
+type TimeConfig struct {
+	// Precision is the fractional seconds precision of column from 0 to 6, eg. `datetime(6)`, `timestamp(6)`
+	Precision int
+	// TimestampTZ will store the time as `timestamptz` on postgres, it's ignored by mysql
+	TimestampTZ bool
+	// Location is the location of the loaded time, default is UTC
+	Location *time.Location
+}
+
+func (c TimeConfig) precision(t tag) int {
+	p := c.Precision
+	if v, err := strconv.Atoi(t.Get("precision")); err == nil {
+		p = v
+	}
+	switch {
+	case p < 0:
+		return 0
+	case p > 6:
+		return 6
+	}
+	return p
+}
+
+func (c TimeConfig) isTimestampTZ(t tag) bool {
+	return c.TimestampTZ || t.options["timestamptz"]
+}
+
+// locations is the cache of the location which loaded from the tag
+var locations sync.Map
+
+func (c TimeConfig) location(t tag) *time.Location {
+	if name := t.Get("location"); name != "" {
+		if loc, isOk := locations.Load(name); isOk {
+			return loc.(*time.Location)
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			locations.Store(name, loc)
+			return loc
+		}
+	}
+	if c.Location != nil {
+		return c.Location
+	}
+	return time.UTC
+}
+
+// SetTimeConfig : set the storage of `time.Time`, it should be set before the connection is used
+func (db *DB) SetTimeConfig(c TimeConfig) {
+	if db.client.timeConf == nil {
+		db.client.timeConf = new(TimeConfig)
+	}
+	*db.client.timeConf = c
+}
+
+func (c Client) timeConfig() TimeConfig {
+	if c.timeConf == nil {
+		return TimeConfig{}
+	}
+	return *c.timeConf
+}
+
+// formatTime will format the time in UTC with the fractional seconds precision, the exceed digits are truncated
+func formatTime(t time.Time, precision int) string {
+	layout := "2006-01-02 15:04:05"
+	if precision > 0 {
+		layout += "." + strings.Repeat("0", precision)
+	}
+	return t.UTC().Format(layout)
+}
+
+// parseTime will parse the date time column, the offset is applied if it's present, eg. timestamptz of postgres
+func parseTime(str string) (time.Time, error) {
+	for _, layout := range []string{
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02 15:04:05Z07",
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05",
+	} {
+		if dt, err := time.Parse(layout, str); err == nil {
+			return dt.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("goloquent: unable to parse %q to date time", str)
+}
+
+// timeOf will return the time of value, the nil pointer is not a time
+func timeOf(it interface{}) (time.Time, bool) {
+	switch vi := it.(type) {
+	case time.Time:
+		return vi, true
+	case *time.Time:
+		if vi != nil {
+			return *vi, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// inLocation will convert the decoded time to the location, the pointer and slice of time are converted as well
+func inLocation(it interface{}, loc *time.Location) interface{} {
+	switch vi := it.(type) {
+	case time.Time:
+		return vi.In(loc)
+	case *interface{}:
+		if vi != nil {
+			v := inLocation(*vi, loc)
+			return &v
+		}
+	case []interface{}:
+		for i := range vi {
+			vi[i] = inLocation(vi[i], loc)
+		}
+	}
+	return it
+}
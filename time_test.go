@@ -0,0 +1,180 @@
+package goloquent
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+func TestFormatTime(t *testing.T) {
+	dt := time.Date(2020, 1, 2, 11, 4, 5, 123456789, time.FixedZone("MYT", 8*3600))
+	if formatTime(dt, 0) != "2020-01-02 03:04:05" || formatTime(dt, 3) != "2020-01-02 03:04:05.123" ||
+		formatTime(dt, 6) != "2020-01-02 03:04:05.123456" {
+		t.Fatalf(errUnexpectedResult, "formatTime")
+	}
+	if new(postgres).TimeValue(dt, 0) != "2020-01-02 03:04:05+00:00" {
+		t.Fatalf(errUnexpectedResult, "postgres.TimeValue")
+	}
+
+	utc := time.Date(2020, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	for _, str := range []string{
+		"2020-01-02 03:04:05.123456",
+		"2020-01-02 11:04:05.123456+08",
+		"2020-01-02 11:04:05.123456+08:00",
+		"2020-01-02T03:04:05.123456Z",
+	} {
+		dt, err := parseTime(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !dt.Equal(utc) || dt.Location() != time.UTC {
+			t.Fatalf("unexpected time %v of %q", dt, str)
+		}
+	}
+	if baseToInterface(utc) != "2020-01-02 03:04:05.123456" {
+		t.Fatalf(errUnexpectedResult, "baseToInterface")
+	}
+}
+
+type testEvent struct {
+	Key       *datastore.Key `goloquent:"__key__"`
+	StartAt   time.Time      `goloquent:",precision=3,timestamptz"`
+	EndAt     *time.Time
+	LocalTime time.Time `goloquent:",location=Asia/Kuala_Lumpur"`
+}
+
+func TestTimeConfig(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Kuala_Lumpur")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	db := newFakeDB(t, new(postgres))
+	db.SetTimeConfig(TimeConfig{Precision: 6, Location: time.FixedZone("JST", 9*3600)})
+	codec, err := getStructCodec(&testEvent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"StartAt":   "timestamptz(3)",
+		"EndAt":     "timestamp(6)",
+		"LocalTime": "timestamp(6)",
+	}
+	for _, c := range getColumns(nil, codec) {
+		if dt, isOk := expected[c.Name()]; isOk && db.dialect.GetSchema(c)[0].DataType != dt {
+			t.Fatalf("unexpected schema of %q, %s", c.Name(), db.dialect.GetSchema(c)[0].DataType)
+		}
+	}
+
+	dt := time.Date(2020, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	props, err := SaveStruct(&testEvent{StartAt: dt, EndAt: &dt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := newBuilder(db.NewQuery())
+	if v, _ := b.propertyValue(props["StartAt"]); v != "2020-01-02 03:04:05.123+00:00" {
+		t.Fatalf(errUnexpectedResult, "propertyValue")
+	}
+	if v, _ := b.propertyValue(props["EndAt"]); v != "2020-01-02 03:04:05.123456+00:00" {
+		t.Fatalf(errUnexpectedResult, "propertyValue")
+	}
+
+	it := &Iterator{
+		columns: []string{keyFieldName, "StartAt", "EndAt", "LocalTime"},
+		results: []map[string][]byte{{
+			"StartAt":   []byte("2020-01-02 03:04:05.123+00"),
+			"EndAt":     []byte("2020-01-02 03:04:05.123456"),
+			"LocalTime": []byte("2020-01-02 03:04:05"),
+		}},
+		timeConf: db.client.timeConfig(),
+	}
+	var e testEvent
+	if _, err := it.scan(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.StartAt.Location().String() != "JST" || e.StartAt.Hour() != 12 || e.StartAt.Nanosecond() != 123000000 {
+		t.Fatalf(errUnexpectedResult, "Iterator.scan")
+	}
+	if e.EndAt == nil || !e.EndAt.Equal(dt.Truncate(time.Microsecond)) {
+		t.Fatalf(errUnexpectedResult, "Iterator.scan")
+	}
+	if e.LocalTime.Location().String() != loc.String() || e.LocalTime.Hour() != 11 {
+		t.Fatalf(errUnexpectedResult, "Iterator.scan")
+	}
+
+	s, err := db.Table("Event").Where("StartAt", ">", dt).ToSQL(OpGet, &[]testEvent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Arguments(), []interface{}{"2020-01-02 03:04:05.123+00:00"}) {
+		t.Fatalf(errUnexpectedResult, "ToSQL")
+	}
+}
+
+func TestTimeFilterPrecision(t *testing.T) {
+	type Order struct {
+		Key       *datastore.Key `goloquent:"__key__"`
+		CreatedAt time.Time
+		PaidAt    time.Time `goloquent:",precision=3"`
+	}
+
+	db := newFakeDB(t)
+	dt := time.Date(2020, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+	// the column without precision stores the truncated seconds, so the equality must match the truncated value
+	for field, want := range map[string]string{
+		"CreatedAt": "2020-01-02 03:04:05",
+		"PaidAt":    "2020-01-02 03:04:05.123",
+	} {
+		s, err := db.Table("Order").Where(field, "=", dt).ToSQL(OpGet, &[]Order{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(s.Arguments(), []interface{}{want}) {
+			t.Fatalf("unexpected arguments of %q, %v", field, s.Arguments())
+		}
+	}
+
+	// no model is used with the statement, the precision of `TimeConfig` is used
+	s, err := db.Table("Order").Where("PaidAt", "<", dt).ToSQL(OpFlush)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Arguments(), []interface{}{"2020-01-02 03:04:05"}) {
+		t.Fatalf("unexpected arguments %v", s.Arguments())
+	}
+
+	db.SetTimeConfig(TimeConfig{Precision: 6})
+	s, err = db.Table("Order").Where("PaidAt", "<", dt).ToSQL(OpFlush)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Arguments(), []interface{}{"2020-01-02 03:04:05.123456"}) {
+		t.Fatalf("unexpected arguments %v", s.Arguments())
+	}
+}
+
+func TestTimeEntityCache(t *testing.T) {
+	type Member struct {
+		Key *datastore.Key `goloquent:"__key__"`
+		// the cached time must be same as the time stored in database, which is truncated to the precision
+		JoinedAt time.Time `goloquent:",precision=3"`
+	}
+
+	db := newFakeDB(t)
+	db.SetEntityCache(NewLRUCache(10))
+
+	m := &Member{Key: datastore.NameKey("Member", "m1", nil), JoinedAt: time.Date(2026, 10, 19, 8, 30, 15, 123456789, time.UTC)}
+	if err := db.Create(m); err != nil {
+		t.Fatal(err)
+	}
+	result := new(Member)
+	if err := db.Find(m.Key, result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.JoinedAt.Equal(time.Date(2026, 10, 19, 8, 30, 15, 123000000, time.UTC)) {
+		t.Fatalf("cached time should be truncated to the precision, but got %v", result.JoinedAt)
+	}
+}
@@ -214,6 +214,26 @@ func splitKey(k *datastore.Key) (key string, parent string) {
 	return k.Kind + ",'" + name + "'", stringifyKey(k.Parent)
 }
 
+// escapeLike will escape the wildcard characters of `LIKE` pattern
+func escapeLike(str string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(str)
+}
+
+// jsonPathColumn will return the json path in filter format, such as `Address>region.regionCode`
+func jsonPathColumn(field, path string) string {
+	return field + jsonDelimeter + path
+}
+
+// isJSONPathColumn will check whether the column is the generated column of json index
+func isJSONPathColumn(col string) bool {
+	return strings.Contains(col, jsonDelimeter)
+}
+
+// jsonIndexName will return the index name of json path without suffix
+func jsonIndexName(table, field, path string) string {
+	return fmt.Sprintf("%s_%s_%s", table, field, strings.Replace(path, ".", "_", -1))
+}
+
 func stringPk(k *datastore.Key) string {
 	kk, pp := splitKey(k)
 	return strings.Trim(pp+keyDelimeter+kk, keyDelimeter)
@@ -45,3 +45,10 @@ func TestEscapeSingleQuote(t *testing.T) {
 		t.Fatal(`Unexpected error occur in "escapeSingleQuote"`)
 	}
 }
+
+func TestEscapeLike(t *testing.T) {
+	str := `Parent,'a_b%25'/Child,10\`
+	if escapeLike(str) != `Parent,'a\_b\%25'/Child,10\\` {
+		t.Fatal(`Unexpected error occur in "escapeLike"`)
+	}
+}